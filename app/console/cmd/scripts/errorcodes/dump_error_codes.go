@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"response-std/app/pkg/response"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: go run app/console/cmd/scripts/errorcodes/dump_error_codes.go [json|markdown] [output_path]")
+		os.Exit(1)
+	}
+
+	format := strings.ToLower(os.Args[1])
+
+	// Default ke stdout, atau tulis ke file jika output_path diisi
+	var out io.Writer = os.Stdout
+	if len(os.Args) >= 3 {
+		outputPath := os.Args[2]
+		if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+			fmt.Printf("Failed to create directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Printf("Failed to create file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	codes := response.ErrorCodes()
+
+	var err error
+	switch format {
+	case "json":
+		err = writeJSON(out, codes)
+	case "markdown", "md":
+		err = writeMarkdown(out, codes)
+	default:
+		fmt.Printf("Unsupported format: %s (use json or markdown)\n", format)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Failed to write catalog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func writeJSON(w io.Writer, codes []response.ErrorCodeInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(codes)
+}
+
+func writeMarkdown(w io.Writer, codes []response.ErrorCodeInfo) error {
+	var b strings.Builder
+	b.WriteString("# Error Codes\n\n")
+	b.WriteString("| Code | Status | Message | Description |\n")
+	b.WriteString("|------|--------|---------|-------------|\n")
+	for _, info := range codes {
+		fmt.Fprintf(&b, "| `%s` | %d | %s | %s |\n", info.Code, info.Status, info.Message, info.Description)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
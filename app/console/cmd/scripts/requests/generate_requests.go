@@ -114,7 +114,7 @@ func (r *{{.CamelCase}}Request) Validate(c *gin.Context) bool {
 			errorInterface[k] = v
 		}
 
-		services.AppLogger.Debug("Validation failed", errorInterface)
+		services.AppLogger.WithContext(c).Debug("Validation failed", errorInterface)
 		spew.Dump(errors, "Validation errors", "\n errors from validation", errorInterface)
 
 		response.UnprocessableValidation(c, "Validation failed", nil, errorInterface, "[{{.CamelCase}}Request.Validate]")
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"response-std/app/pkg/auth"
+	"response-std/config"
+)
+
+// Hapus personal access token yang sudah kadaluarsa atau tidak dipakai selama N hari,
+// serta refresh token yang sudah kadaluarsa.
+// N diambil dari argumen pertama, default TOKEN_PRUNE_DAYS. 0 = hanya token kadaluarsa.
+func main() {
+	config.InitConfig()
+	config.LoadDBMysql()
+
+	if config.DB == nil {
+		log.Fatal("config.DB is nil after InitDB")
+	}
+
+	days := config.ENV.TokenPruneDays
+	if len(os.Args) >= 2 && os.Args[1] != "" {
+		parsed, err := strconv.Atoi(os.Args[1])
+		if err != nil || parsed < 0 {
+			fmt.Println("Usage: go run app/console/cmd/scripts/tokens/prune_tokens.go [days]")
+			os.Exit(1)
+		}
+		days = parsed
+	}
+
+	deleted, err := auth.PruneTokens(config.DB, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		log.Fatalf("Failed to prune tokens: %v", err)
+	}
+
+	refreshDeleted, err := auth.PruneRefreshTokens(config.DB)
+	if err != nil {
+		log.Fatalf("Failed to prune refresh tokens: %v", err)
+	}
+	log.Printf("Pruned %d expired refresh tokens.", refreshDeleted)
+
+	if days > 0 {
+		log.Printf("Pruned %d tokens (expired or unused for %d days).", deleted, days)
+		return
+	}
+	log.Printf("Pruned %d expired tokens.", deleted)
+}
@@ -1,7 +1,10 @@
 package helper
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -49,6 +52,34 @@ func StringPtr(s string) *string {
 	return &s
 }
 
+// ParseByteSize mengubah ukuran seperti "512", "64KB", "1MB", "1.5GB" menjadi jumlah byte (basis 1024)
+// contoh penggunaan:
+// limit, err := ParseByteSize("1MB") // 1048576
+func ParseByteSize(raw string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(raw))
+	units := []struct {
+		suffix string
+		size   float64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+
+	multiplier := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.size
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", raw)
+	}
+	return int64(number * multiplier), nil
+}
+
 // Helper function to check if method is valid
 func IsValidHTTPMethod(method string) bool {
 	validMethods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
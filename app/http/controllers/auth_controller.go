@@ -4,17 +4,21 @@ package controllers
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
+	"errors"
 	"log"
-	"math/rand"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
-	"response-std/app/helpers/helper"
 	"response-std/app/http/requests/auth"
 	"response-std/app/models/entities"
+	authpkg "response-std/app/pkg/auth"
+	"response-std/app/pkg/i18n"
+	"response-std/app/pkg/lockout"
 	"response-std/app/pkg/permissions"
 	"response-std/app/pkg/response"
+	"response-std/libs/external/services"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/gin-gonic/gin"
@@ -25,8 +29,6 @@ import (
 type AuthController struct{}
 
 func NewAuthController() *AuthController {
-	// Seed random generator untuk keamanan
-	rand.Seed(time.Now().UnixNano())
 	return &AuthController{}
 }
 
@@ -39,11 +41,14 @@ func (a *AuthController) Login(db *gorm.DB) gin.HandlerFunc {
 			response.InternalServerError(c, "Database connection is not initialized", nil, "[Login]")
 			return
 		}
+		// Query ikut dibatalkan saat request timeout / client disconnect
+		db := db.WithContext(c.Request.Context())
+
 		// Validate request using LoginRequest
 		var loginReq auth.LoginRequest
 		// BIND JSON DULU
 		if err := c.ShouldBindJSON(&loginReq); err != nil {
-			response.BadRequest(c, "Invalid request format", err, "[Login]")
+			response.BadRequest(c, "auth.invalid_request", response.WithCode(response.CodeRequestMalformed, err), "[Login]")
 			return
 		}
 
@@ -52,6 +57,13 @@ func (a *AuthController) Login(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Brute-force protection: tolak lebih awal jika username/IP sedang dikunci atau harus menunggu
+		guard := lockout.Default()
+		if decision := guard.Check(c, loginReq.Username, c.ClientIP()); !decision.Allowed() {
+			rejectLogin(c, decision)
+			return
+		}
+
 		// Determine if username is email or name (same logic as Laravel)
 		var loginField string
 		if strings.Contains(loginReq.Username, "@") {
@@ -65,66 +77,44 @@ func (a *AuthController) Login(db *gorm.DB) gin.HandlerFunc {
 			Where(loginField+" = ?", loginReq.Username).First(&user).Error
 
 		if err != nil {
-			response.UnprocessableEntity(c, "Invalid credentials", err, "[Login]")
+			if decision := guard.Fail(c, loginReq.Username, c.ClientIP()); decision.Locked {
+				rejectLogin(c, decision)
+				return
+			}
+			response.UnprocessableEntity(c, "auth.invalid_credentials", response.WithCode(response.CodeAuthInvalidCredentials, err), "[Login]")
 			return
 		}
 
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
-			response.UnprocessableEntity(c, "Invalid credentials", err, "[Login]")
+			if decision := guard.Fail(c, loginReq.Username, c.ClientIP()); decision.Locked {
+				rejectLogin(c, decision)
+				return
+			}
+			response.UnprocessableEntity(c, "auth.invalid_credentials", response.WithCode(response.CodeAuthInvalidCredentials, err), "[Login]")
 			return
 		}
 
-		// Generate token
-		plainToken := generateSanctumToken()
-		hashedToken := sha256.Sum256([]byte(plainToken))
-		hashedTokenHex := hex.EncodeToString(hashedToken[:])
-
-		// Set expires (12 jam dari sekarang)
-		expiresAt := time.Now().Add(12 * time.Hour)
-
-		token := entities.PersonalAccessTokens{
-			TokenableID:   user.ID,
-			TokenableType: "App\\entities\\User",
-			Name:          "go-client",
-			Token:         hashedTokenHex,
-			Abilities:     helper.StringPtr("['*']"),
-			ExpiresAt:     &expiresAt,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
-		}
-
-		// Use transaction to ensure data consistency
-		err = db.Transaction(func(tx *gorm.DB) error {
-			if err := tx.Create(&token).Error; err != nil {
-				return err
-			}
-			return nil
-		})
+		guard.Succeed(c, loginReq.Username)
 
+		// Access token berumur pendek (ACCESS_TOKEN_TTL) + refresh token sekali pakai (REFRESH_TOKEN_TTL)
+		abilities := authpkg.NormalizeAbilities(loginReq.Abilities)
+		session, err := authpkg.IssueSession(db, user.ID, "go-client", abilities, authpkg.ClientFromRequest(c), authpkg.DefaultSessionTTL())
 		if err != nil {
-			response.InternalServerError(c, "Failed to create token", err, "[Login]")
+			response.InternalServerError(c, "auth.token_create_failed", err, "[Login]")
 			return
 		}
 
-		if token.ID == 0 {
-			response.InternalServerError(c, "Failed to get token", nil, "[Login]")
-			return
-		}
-
-		accessToken := fmt.Sprintf("%d|%s", token.ID, plainToken)
-
 		res := gin.H{
-			"name":  user.Name,
-			"email": user.Email,
-			"token": accessToken,
-			"role":  getPrimaryRole(user.Roles),
-			"session": gin.H{
-				"expires_at": expiresAt.Format(time.RFC3339Nano),
-				"expired_in": 24,
-			},
+			"name":          user.Name,
+			"email":         user.Email,
+			"token":         session.AccessToken,
+			"refresh_token": session.RefreshToken,
+			"abilities":     session.Abilities,
+			"role":          getPrimaryRole(user.Roles),
+			"session":       sessionInfo(session),
 		}
 
-		response.Success(c, "Login successful. Welcome Bro 🔥✌️", res)
+		response.Success(c, "auth.login_success", res)
 	}
 }
 
@@ -133,15 +123,35 @@ func (a *AuthController) Login(db *gorm.DB) gin.HandlerFunc {
 // ---------------------------
 func (a *AuthController) Logout(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Query ikut dibatalkan saat request timeout / client disconnect
+		db := db.WithContext(c.Request.Context())
+
+		// JWT: jti masuk denylist dan refresh token sesi (sid) dicabut
+		if claims, ok := authpkg.CurrentClaims(c); ok {
+			if err := authpkg.DefaultJWT().Revoke(c.Request.Context(), claims); err != nil {
+				response.InternalServerError(c, "auth.logout_failed", err, "[Logout]")
+				return
+			}
+			if claims.SessionID != "" {
+				if err := authpkg.RevokeFamily(db, claims.SessionID); err != nil {
+					response.InternalServerError(c, "auth.logout_failed", err, "[Logout]")
+					return
+				}
+			}
+
+			response.Success(c, "auth.logout_success", nil)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			response.Unauthorized(c, "Token tidak valid", nil, "[Logout]")
+			response.Unauthorized(c, "auth.token_invalid", nil, "[Logout]")
 			return
 		}
 
 		parts := strings.SplitN(strings.TrimPrefix(authHeader, "Bearer "), "|", 2)
 		if len(parts) != 2 {
-			response.Unauthorized(c, "Token tidak lengkap", nil, "[Logout]")
+			response.Unauthorized(c, "auth.token_incomplete", nil, "[Logout]")
 			return
 		}
 
@@ -152,17 +162,23 @@ func (a *AuthController) Logout(db *gorm.DB) gin.HandlerFunc {
 		var token entities.PersonalAccessTokens
 		err := db.Where("id = ? AND token = ?", tokenID, hashedHex).First(&token).Error
 		if err != nil {
-			response.Unauthorized(c, "Token tidak dikenali", err, "[Logout]")
+			response.Unauthorized(c, "auth.token_unknown", err, "[Logout]")
 			return
 		}
 
 		// Hapus token dari database
 		if err := db.Delete(&token).Error; err != nil {
-			response.InternalServerError(c, "Failed to logout", err, "[Logout]")
+			response.InternalServerError(c, "auth.logout_failed", err, "[Logout]")
+			return
+		}
+
+		// Refresh token dari sesi yang sama ikut dicabut agar tidak bisa membuat access token baru
+		if err := authpkg.RevokeFamiliesOf(db, token.ID); err != nil {
+			response.InternalServerError(c, "auth.logout_failed", err, "[Logout]")
 			return
 		}
 
-		response.Success(c, "Logout berhasil", nil)
+		response.Success(c, "auth.logout_success", nil)
 	}
 }
 
@@ -171,11 +187,14 @@ func (a *AuthController) Logout(db *gorm.DB) gin.HandlerFunc {
 // ---------------------------
 func (a *AuthController) Register(db *gorm.DB, spatie *permissions.Spatie) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Query ikut dibatalkan saat request timeout / client disconnect
+		db := db.WithContext(c.Request.Context())
+
 		// Validate request using RegisterRequest
 		var registerReq auth.RegisterRequest
 		// BIND JSON DULU
 		if err := c.ShouldBindJSON(&registerReq); err != nil {
-			response.BadRequest(c, "Invalid request format", err, "[Register]")
+			response.BadRequest(c, "auth.invalid_request", response.WithCode(response.CodeRequestMalformed, err), "[Register]")
 			return
 		}
 		// VALIDATE
@@ -188,7 +207,7 @@ func (a *AuthController) Register(db *gorm.DB, spatie *permissions.Spatie) gin.H
 		var count int64
 		db.Model(&entities.User{}).Where("email = ?", registerReq.Email).Count(&count)
 		if count > 0 {
-			response.UnprocessableEntity(c, "Email sudah digunakan", nil, "[Register]")
+			response.UnprocessableEntity(c, "auth.email_taken", response.WithCode(response.CodeUserEmailTaken, nil), "[Register]")
 			return
 		}
 
@@ -196,7 +215,7 @@ func (a *AuthController) Register(db *gorm.DB, spatie *permissions.Spatie) gin.H
 		// Laravel default menggunakan cost 12, tapi 10-12 sudah cukup aman
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerReq.Password), 12)
 		if err != nil {
-			response.InternalServerError(c, "Gagal memproses password", err, "[Register]")
+			response.InternalServerError(c, "auth.password_hash_failed", err, "[Register]")
 			return
 		}
 
@@ -207,17 +226,17 @@ func (a *AuthController) Register(db *gorm.DB, spatie *permissions.Spatie) gin.H
 		}
 
 		if err := db.Create(&user).Error; err != nil {
-			response.UnprocessableEntity(c, "Gagal mendaftar", err, "[Register]")
+			response.UnprocessableEntity(c, "auth.register_failed", response.WithCode(response.CodeAuthRegistrationFailure, err), "[Register]")
 			return
 		}
 
 		// assign default role (misal: user)
 		defaultRole := "user"
-		if err := spatie.AssignRole(user.ID, defaultRole); err != nil {
+		if err := spatie.WithContext(c.Request.Context()).AssignRole(user.ID, defaultRole); err != nil {
 			log.Printf("Failed to assign role %s to %s: %v", defaultRole, user.Name, err)
 		}
 
-		response.Created(c, "Akun berhasil didaftarkan, silahkan login!", nil)
+		response.Created(c, "auth.register_success", nil)
 	}
 }
 
@@ -227,17 +246,17 @@ func (a *AuthController) Register(db *gorm.DB, spatie *permissions.Spatie) gin.H
 func (a *AuthController) Me(c *gin.Context, spatie *permissions.Spatie) {
 	user, exists := c.Get("user")
 	if !exists {
-		response.Unauthorized(c, "Unauthenticated", nil, "[Me]")
+		response.Unauthorized(c, "auth.unauthenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[Me]")
 		return
 	}
 
 	u, ok := user.(entities.User)
 	if !ok {
-		response.NotFound(c, "User not found", nil, "[Me]")
+		response.NotFound(c, "auth.user_not_found", nil, "[Me]")
 		return
 	}
 
-	userRoles, err := spatie.GetUserRoles(u.ID)
+	userRoles, err := spatie.WithContext(c.Request.Context()).GetUserRoles(u.ID)
 	if err != nil {
 		spew.Dump("apa nih", err)
 		data := gin.H{
@@ -247,7 +266,7 @@ func (a *AuthController) Me(c *gin.Context, spatie *permissions.Spatie) {
 			"roles": u.Roles, // Tambahkan roles jika dibutuhkan
 		}
 
-		response.Success(c, "User fetched!", data)
+		response.Success(c, "auth.user_fetched", data)
 		return
 	}
 
@@ -258,89 +277,122 @@ func (a *AuthController) Me(c *gin.Context, spatie *permissions.Spatie) {
 		"roles": userRoles, // Tambahkan roles jika dibutuhkan
 	}
 
-	response.Success(c, "User fetched!", data)
+	response.Success(c, "auth.user_fetched", data)
 }
 
 // ---------------------------
-// REFRESH TOKEN (Optional)
+// REFRESH TOKEN (rotation)
 // ---------------------------
+// RefreshToken menukar refresh token dengan access token + refresh token baru (public, tanpa Bearer).
+// Refresh token sekali pakai: replay token lama mencabut seluruh sesi (family) tersebut.
 func (a *AuthController) RefreshToken(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Dapatkan user dari middleware auth
-		user, exists := c.Get("user")
-		if !exists {
-			response.Unauthorized(c, "Unauthenticated", nil, "[RefreshToken]")
+		// Query ikut dibatalkan saat request timeout / client disconnect
+		db := db.WithContext(c.Request.Context())
+
+		var req struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "auth.invalid_request", response.WithCode(response.CodeRequestMalformed, err), "[RefreshToken]")
 			return
 		}
 
-		u, ok := user.(entities.User)
-		if !ok {
-			response.NotFound(c, "User not found", nil, "[RefreshToken]")
+		session, err := authpkg.RotateRefreshToken(db, req.RefreshToken, authpkg.ClientFromRequest(c), authpkg.DefaultSessionTTL())
+		switch {
+		case errors.Is(err, authpkg.ErrRefreshTokenInvalid):
+			response.Unauthorized(c, "auth.refresh_token_invalid", response.WithCode(response.CodeAuthRefreshTokenInvalid, err), "[RefreshToken]")
+			return
+		case errors.Is(err, authpkg.ErrRefreshTokenExpired):
+			response.Unauthorized(c, "auth.refresh_token_expired", response.WithCode(response.CodeAuthRefreshTokenExpired, err), "[RefreshToken]")
+			return
+		case errors.Is(err, authpkg.ErrRefreshTokenReused):
+			services.AppLogger.WithContext(c).Warn("Refresh token reuse detected, token family revoked", map[string]interface{}{
+				"client_ip":  c.ClientIP(),
+				"user_agent": c.Request.UserAgent(),
+			})
+			response.Unauthorized(c, "auth.refresh_token_reused", response.WithCode(response.CodeAuthRefreshTokenReused, err), "[RefreshToken]")
+			return
+		case err != nil:
+			response.InternalServerError(c, "auth.token_create_failed", err, "[RefreshToken]")
 			return
 		}
 
-		// Hapus token lama
-		authHeader := c.GetHeader("Authorization")
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			parts := strings.SplitN(strings.TrimPrefix(authHeader, "Bearer "), "|", 2)
-			if len(parts) == 2 {
-				tokenID := parts[0]
-				db.Where("id = ?", tokenID).Delete(&entities.PersonalAccessTokens{})
-			}
+		res := gin.H{
+			"token":         session.AccessToken,
+			"refresh_token": session.RefreshToken,
+			"abilities":     session.Abilities,
+			"session":       sessionInfo(session),
 		}
 
-		// Generate token baru
-		plainToken := generateSanctumToken()
-		hashedToken := sha256.Sum256([]byte(plainToken))
-		hashedTokenHex := hex.EncodeToString(hashedToken[:])
-
-		expiresAt := time.Now().Add(24 * time.Hour)
-
-		token := entities.PersonalAccessTokens{
-			TokenableID:   u.ID,
-			TokenableType: "App\\entities\\User",
-			Name:          "go-client",
-			Token:         hashedTokenHex,
-			Abilities:     helper.StringPtr("['*']"),
-			ExpiresAt:     &expiresAt,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
-		}
+		response.Success(c, "auth.token_refreshed", res)
+	}
+}
 
-		if err := db.Create(&token).Error; err != nil {
-			response.InternalServerError(c, "Failed to create token", err, "[RefreshToken]")
+// ---------------------------
+// UNLOCK LOGIN (Admin)
+// ---------------------------
+func (a *AuthController) UnlockLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Username string `json:"username"`
+			IP       string `json:"ip"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "auth.invalid_request", response.WithCode(response.CodeRequestMalformed, err), "[UnlockLogin]")
+			return
+		}
+		if strings.TrimSpace(req.Username) == "" && strings.TrimSpace(req.IP) == "" {
+			response.UnprocessableValidation(c, "validation.failed", nil, map[string]interface{}{
+				"username": []string{i18n.T(c, "auth.unlock_target_required")},
+			}, "[UnlockLogin]")
 			return
 		}
 
-		accessToken := fmt.Sprintf("%d|%s", token.ID, plainToken)
-
-		res := gin.H{
-			"token": accessToken,
-			"session": gin.H{
-				"expires_at": expiresAt.Format(time.RFC3339Nano),
-				"expired_in": 24,
-			},
+		if err := lockout.Default().Unlock(c, req.Username, req.IP); err != nil {
+			response.InternalServerError(c, "Failed to clear login lockout", err, "[UnlockLogin]")
+			return
 		}
 
-		response.Success(c, "Token berhasil di-refresh", res)
+		response.Success(c, "auth.unlock_success", gin.H{
+			"username": req.Username,
+			"ip":       req.IP,
+		})
 	}
 }
 
 // ---------------------------
 // UTILITIES
 // ---------------------------
+// rejectLogin menjawab 423 (dikunci) atau 429 (delay progresif) dengan header Retry-After
+func rejectLogin(c *gin.Context, decision lockout.Decision) {
+	seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	wait := (time.Duration(seconds) * time.Second).String()
+
+	if decision.Unavailable {
+		response.ServiceUnavailable(c, i18n.T(c, "auth.login_unavailable", wait), response.WithCode(response.CodeAuthLoginUnavailable, nil), "[Login]")
+		return
+	}
+	if decision.Locked {
+		response.Locked(c, i18n.T(c, "auth.account_locked", wait), response.WithCode(response.CodeAuthAccountLocked, nil), "[Login]")
+		return
+	}
+	response.TooManyRequests(c, i18n.T(c, "auth.too_many_attempts", wait), response.WithCode(response.CodeAuthTooManyAttempts, nil), "[Login]")
+}
+
+// sessionInfo: expired_in dalam detik, dihitung dari ACCESS_TOKEN_TTL
+func sessionInfo(session authpkg.Session) gin.H {
+	return gin.H{
+		"expires_at":         session.AccessExpiresAt.Format(time.RFC3339Nano),
+		"expired_in":         int(time.Until(session.AccessExpiresAt).Round(time.Second).Seconds()),
+		"refresh_expires_at": session.RefreshExpiresAt.Format(time.RFC3339Nano),
+	}
+}
+
 func getPrimaryRole(roles []entities.Roles) string {
 	if len(roles) > 0 {
 		return roles[0].Name
 	}
 	return ""
 }
-
-func generateSanctumToken() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 48) // 48 karakter seperti Sanctum default
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
-	}
-	return string(b)
-}
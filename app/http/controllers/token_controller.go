@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"response-std/app/models/entities"
+	"response-std/app/pkg/auth"
+	"response-std/app/pkg/i18n"
+	"response-std/app/pkg/response"
+	"response-std/libs/responses"
+)
+
+// TokenController mengelola personal access token: milik sendiri (/auth/tokens)
+// dan milik user lain untuk admin (/admin/users/:id/tokens)
+type TokenController struct {
+	DB *gorm.DB
+}
+
+func NewTokenController(db *gorm.DB) *TokenController {
+	return &TokenController{DB: db}
+}
+
+type createTokenInput struct {
+	Name      string     `json:"name" binding:"required,max=255"`
+	Abilities []string   `json:"abilities"`
+	ExpiresAt *time.Time `json:"expires_at"` // kosong = tidak kadaluarsa (untuk CI / integrasi)
+}
+
+// ---------------------------
+// TOKEN MILIK SENDIRI
+// ---------------------------
+func (ctl *TokenController) ListTokens(c *gin.Context) error {
+	user, ok := auth.GetAuthenticatedUser(c)
+	if !ok {
+		return response.ErrorFromCode(response.CodeAuthUnauthenticated, nil)
+	}
+	return ctl.list(c, user.ID)
+}
+
+func (ctl *TokenController) CreateToken(c *gin.Context) error {
+	user, ok := auth.GetAuthenticatedUser(c)
+	if !ok {
+		return response.ErrorFromCode(response.CodeAuthUnauthenticated, nil)
+	}
+	// Token baru tidak boleh punya ability melebihi token yang membuatnya
+	return ctl.create(c, user.ID, auth.TokenAbilities(c))
+}
+
+func (ctl *TokenController) RevokeToken(c *gin.Context) error {
+	user, ok := auth.GetAuthenticatedUser(c)
+	if !ok {
+		return response.ErrorFromCode(response.CodeAuthUnauthenticated, nil)
+	}
+	return ctl.revoke(c, user.ID)
+}
+
+// RevokeOtherTokens menghapus semua token user kecuali token yang sedang dipakai
+func (ctl *TokenController) RevokeOtherTokens(c *gin.Context) error {
+	user, ok := auth.GetAuthenticatedUser(c)
+	if !ok {
+		return response.ErrorFromCode(response.CodeAuthUnauthenticated, nil)
+	}
+	return ctl.revokeAll(c, user.ID)
+}
+
+// ---------------------------
+// ADMIN: TOKEN MILIK USER LAIN
+// ---------------------------
+func (ctl *TokenController) ListUserTokens(c *gin.Context) error {
+	userID, err := ctl.targetUser(c)
+	if err != nil {
+		return err
+	}
+	return ctl.list(c, userID)
+}
+
+func (ctl *TokenController) CreateUserToken(c *gin.Context) error {
+	userID, err := ctl.targetUser(c)
+	if err != nil {
+		return err
+	}
+	// Admin juga tidak bisa memberi ability melebihi token yang dipakainya, agar PAT admin
+	// yang sempit (mis. users:read) tidak bisa dipakai mencetak token "*"
+	return ctl.create(c, userID, auth.TokenAbilities(c))
+}
+
+func (ctl *TokenController) RevokeUserToken(c *gin.Context) error {
+	userID, err := ctl.targetUser(c)
+	if err != nil {
+		return err
+	}
+	return ctl.revoke(c, userID)
+}
+
+// RevokeUserTokens menghapus semua token user (kecuali token admin sendiri jika target adalah dirinya)
+func (ctl *TokenController) RevokeUserTokens(c *gin.Context) error {
+	userID, err := ctl.targetUser(c)
+	if err != nil {
+		return err
+	}
+	return ctl.revokeAll(c, userID)
+}
+
+// ---------------------------
+// UTILITIES
+// ---------------------------
+func (ctl *TokenController) list(c *gin.Context, userID uint) error {
+	tokens, err := auth.ActiveTokens(ctl.db(c), userID)
+	if err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to fetch tokens", err)
+	}
+
+	response.Success(c, "Tokens retrieved successfully", responses.TokensToResponse(tokens, currentTokenID(c)))
+	return nil
+}
+
+// create membuat token bernama dengan ability yang dibatasi granter (ability token pembuat)
+func (ctl *TokenController) create(c *gin.Context, userID uint, granter auth.Abilities) error {
+	var input createTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return err
+	}
+
+	for _, ability := range input.Abilities {
+		if !auth.ValidAbility(strings.ToLower(strings.TrimSpace(ability))) {
+			return response.ValidationError("Validation failed", map[string]interface{}{
+				"abilities": []string{i18n.T(c, "validation.abilities.invalid", ability)},
+			})
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return response.ValidationError("Validation failed", map[string]interface{}{
+			"expires_at": []string{i18n.T(c, "validation.expires_at.future")},
+		})
+	}
+
+	abilities := auth.NormalizeAbilities(input.Abilities)
+	for _, ability := range abilities {
+		if granter.Cant(ability) {
+			return response.NewError(403, response.CodeAuthAbilityRequired, "Token ability denied. Cannot grant ability: "+ability, nil)
+		}
+	}
+
+	token, plain, err := auth.IssueToken(ctl.db(c), userID, strings.TrimSpace(input.Name), abilities, input.ExpiresAt, auth.ClientFromRequest(c))
+	if err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to create token", err)
+	}
+
+	// Token plain hanya ditampilkan sekali, yang tersimpan di database hanya hash-nya
+	response.Created(c, "Token created successfully", gin.H{
+		"token":      plain,
+		"token_info": responses.TokenToResponse(&token, currentTokenID(c)),
+	})
+	return nil
+}
+
+func (ctl *TokenController) revoke(c *gin.Context, userID uint) error {
+	tokenID, err := strconv.ParseUint(c.Param("token_id"), 10, 64)
+	if err != nil {
+		return response.ErrorFromCode(response.CodeResourceNotFound, err)
+	}
+
+	if err := auth.RevokeToken(ctl.db(c), userID, uint(tokenID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.ErrorFromCode(response.CodeResourceNotFound, err)
+		}
+		return response.NewError(500, response.CodeInternalError, "Failed to revoke token", err)
+	}
+
+	response.Success(c, "Token revoked successfully", nil)
+	return nil
+}
+
+func (ctl *TokenController) revokeAll(c *gin.Context, userID uint) error {
+	revoked, err := auth.RevokeOtherTokens(ctl.db(c), userID, currentTokenID(c))
+	if err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to revoke tokens", err)
+	}
+
+	response.Success(c, "Tokens revoked successfully", gin.H{"revoked": revoked})
+	return nil
+}
+
+// targetUser membaca :id user untuk endpoint admin dan memastikan user-nya ada
+func (ctl *TokenController) targetUser(c *gin.Context) (uint, error) {
+	var user entities.User
+	if err := ctl.db(c).Select("id").First(&user, c.Param("id")).Error; err != nil {
+		return 0, userLookupError(err)
+	}
+	return user.ID, nil
+}
+
+// db mengikat query ke context request, sehingga query dibatalkan saat timeout / client disconnect
+func (ctl *TokenController) db(c *gin.Context) *gorm.DB {
+	return ctl.DB.WithContext(c.Request.Context())
+}
+
+// currentTokenID adalah ID token yang dipakai request ini, 0 jika tidak ada
+func currentTokenID(c *gin.Context) uint {
+	if token, ok := auth.CurrentToken(c); ok {
+		return token.ID
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"response-std/app/http/middleware"
+	"response-std/app/models/entities"
+	"response-std/app/pkg/auth"
+	"response-std/app/pkg/response"
+)
+
+// newAdminTokenRouter memasang route admin token seperti routes/api/v1.go, dengan admin yang
+// login memakai token berisi abilities
+func newAdminTokenRouter(t *testing.T, abilities auth.Abilities) (*gin.Engine, *gorm.DB, entities.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entities.User{}, &entities.PersonalAccessTokens{}); err != nil {
+		t.Fatal(err)
+	}
+	admin := entities.User{Name: "admin", Email: "admin@example.com", Password: "x"}
+	target := entities.User{Name: "bob", Email: "bob@example.com", Password: "x"}
+	db.Create(&admin)
+	db.Create(&target)
+
+	ctl := NewTokenController(db)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			response.FromError(c, c.Errors.Last().Err)
+		}
+	})
+	r.Use(func(c *gin.Context) {
+		c.Set("user", admin)
+		c.Set("abilities", abilities)
+	})
+	r.POST("/admin/users/:id/tokens", middleware.TokenCan("tokens:write"), response.Handle(ctl.CreateUserToken))
+	return r, db, target
+}
+
+func TestCreateUserTokenCapsAbilities(t *testing.T) {
+	cases := []struct {
+		name      string
+		caller    auth.Abilities
+		requested string
+		want      int
+	}{
+		{"missing tokens:write", auth.Abilities{"users:read"}, `["users:read"]`, http.StatusForbidden},
+		{"escalate to wildcard", auth.Abilities{"tokens:write", "users:read"}, `["*"]`, http.StatusForbidden},
+		{"escalate by default abilities", auth.Abilities{"tokens:write", "users:read"}, `[]`, http.StatusForbidden},
+		{"escalate to other scope", auth.Abilities{"tokens:write", "users:read"}, `["users:delete"]`, http.StatusForbidden},
+		{"within caller abilities", auth.Abilities{"tokens:write", "users:read"}, `["users:read"]`, http.StatusCreated},
+		{"full admin token", auth.Abilities{"*"}, `["*"]`, http.StatusCreated},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, db, target := newAdminTokenRouter(t, tc.caller)
+			path := "/admin/users/" + strconv.FormatUint(uint64(target.ID), 10) + "/tokens"
+
+			w := serve(r, http.MethodPost, path, `{"name":"ci","abilities":`+tc.requested+`}`, nil)
+			if w.Code != tc.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body, tc.want)
+			}
+
+			var count int64
+			db.Model(&entities.PersonalAccessTokens{}).Where("tokenable_id = ?", target.ID).Count(&count)
+			if created := count > 0; created != (tc.want == http.StatusCreated) {
+				t.Errorf("token created = %v, want %v", created, tc.want == http.StatusCreated)
+			}
+		})
+	}
+}
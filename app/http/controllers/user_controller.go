@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -8,11 +10,16 @@ import (
 
 	"response-std/app/helpers/helper"
 	"response-std/app/models/entities"
+	"response-std/app/pkg/filter"
+	"response-std/app/pkg/i18n"
+	"response-std/app/pkg/pagination"
 	"response-std/app/pkg/permissions"
+	"response-std/app/pkg/resource"
 	"response-std/app/pkg/response"
 	clientservice "response-std/app/services"
 	"response-std/config"
 	"response-std/libs/external/services"
+	"response-std/libs/responses"
 )
 
 type UserController struct {
@@ -27,44 +34,74 @@ func NewUserController(db *gorm.DB, perm *permissions.Spatie) *UserController {
 	}
 }
 
-func (ctl *UserController) ListUser(c *gin.Context) {
+func (ctl *UserController) ListUser(c *gin.Context) error {
+	params := pagination.FromQuery(c)
+	filters, filterErrs := filter.For(c, entities.User{})
+	if filterErrs != nil {
+		return response.ValidationError("Invalid filter or sort", filterErrs)
+	}
+
+	include, includeErrs := resource.FromQuery(c, responses.UserResource)
+	if includeErrs != nil {
+		return response.ValidationError("Invalid fields or include", includeErrs)
+	}
+
+	query := ctl.db(c).Model(&entities.User{}).Scopes(include.Preload)
+
 	var users []entities.User
-	if err := ctl.DB.Preload("Roles").Find(&users).Error; err != nil {
-		response.InternalServerError(c, "Failed to fetch users", err, "[ListUser]")
-		return
+	var page responses.Pagination
+	var err error
+
+	// Cursor pagination selalu urut berdasarkan id, jadi ?sort= hanya berlaku untuk offset pagination
+	if params.IsCursor() {
+		users, page, err = pagination.CursorPaginate(query.Scopes(filters.WhereScope()), params, "id", func(u entities.User) interface{} {
+			return u.ID
+		})
+	} else {
+		page, err = pagination.Paginate(query.Scopes(filters.Scope()), params, &users)
 	}
 
-	data := make([]gin.H, len(users))
-	for i, user := range users {
-		data[i] = gin.H{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-		}
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return response.NewError(400, response.CodeRequestMalformed, "Invalid cursor", err)
+	}
+	if err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to fetch users", err)
+	}
+
+	data := make([]responses.UserResponse, len(users))
+	for i := range users {
+		data[i] = responses.UserToResponse(&users[i])
 	}
 
 	apiService := clientservice.NewAPIDataService(config.ENV)
-	api_data := apiService.GetUserData()
+	api_data := apiService.GetUserData(c.Request.Context())
 
 	extendedData := &gin.H{
-		"users":    data,
+		"users":    resource.SparseEach(data, include),
 		"external": api_data,
 	}
 
-	response.Success(c, "List of users retrieved successfully", extendedData)
+	response.Paginated(c, "List of users retrieved successfully", extendedData, page)
+	return nil
 }
 
-func (ctl *UserController) GetUserByID(c *gin.Context) {
+func (ctl *UserController) GetUserByID(c *gin.Context) error {
+	include, includeErrs := resource.FromQuery(c, responses.UserResource)
+	if includeErrs != nil {
+		return response.ValidationError("Invalid fields or include", includeErrs)
+	}
+
 	id := c.Param("id")
 	var user entities.User
-	if err := ctl.DB.Preload("Roles").First(&user, id).Error; err != nil {
-		response.NotFound(c, "User not found", err, "[GetUserByID]")
-		return
+	if err := ctl.db(c).Scopes(include.Preload).First(&user, id).Error; err != nil {
+		return userLookupError(err)
 	}
-	response.Success(c, "User retrieved successfully", user)
+	response.SetETag(c, userETag(c, &user))
+	response.Success(c, "User retrieved successfully", resource.Sparse(responses.UserToResponse(&user), include))
+	return nil
 }
 
-func (ctl *UserController) CreateUser(c *gin.Context) {
+func (ctl *UserController) CreateUser(c *gin.Context) error {
 	var input struct {
 		Name     string `json:"name" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
@@ -72,23 +109,20 @@ func (ctl *UserController) CreateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		response.BadRequest(c, "Invalid input", err, "[CreateUser]")
-		return
+		return err
 	}
 
 	// Check if email already exists
 	var count int64
-	ctl.DB.Model(&entities.User{}).Where("email = ?", input.Email).Count(&count)
+	ctl.db(c).Model(&entities.User{}).Where("email = ?", input.Email).Count(&count)
 	if count > 0 {
-		response.UnprocessableEntity(c, "Email already in use", nil, "[CreateUser]")
-		return
+		return response.ErrorFromCode(response.CodeUserEmailTaken, nil)
 	}
 
 	// Hash password
 	hashedPassword, err := helper.HashPassword(input.Password)
 	if err != nil {
-		response.InternalServerError(c, "Failed to hash password", err, "[CreateUser]")
-		return
+		return response.NewError(500, response.CodeInternalError, "Failed to hash password", err)
 	}
 
 	user := entities.User{
@@ -99,57 +133,60 @@ func (ctl *UserController) CreateUser(c *gin.Context) {
 		UpdatedAt: time.Now(),
 	}
 
-	if err := ctl.DB.Create(&user).Error; err != nil {
-		response.InternalServerError(c, "Failed to create user", err, "[CreateUser]")
-		return
+	if err := ctl.db(c).Create(&user).Error; err != nil {
+		return err
 	}
 
+	perm := ctl.Permission.WithContext(c.Request.Context())
 	roleName := "user"
-	_, err = ctl.Permission.FindRoleByName(roleName)
+	_, err = perm.FindRoleByName(roleName)
 	if err != nil {
 		// Role not found, create it
-		_, err = ctl.Permission.CreateRole(roleName, "web")
+		_, err = perm.CreateRole(roleName, "web")
 		if err != nil {
-			response.InternalServerError(c, "Failed to create role for user", err, "[CreateUser]")
-			return
+			return response.NewError(500, response.CodeInternalError, "Failed to create role for user", err)
 		}
 	}
 
-	if err := ctl.Permission.AssignRole(user.ID, roleName); err != nil {
-		response.InternalServerError(c, "Failed to assign role to user", err, "[CreateUser]")
-		return
+	if err := perm.AssignRole(user.ID, roleName); err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to assign role to user", err)
 	}
 
 	response.Success(c, "User created successfully", nil)
+	return nil
 }
 
-func (ctl *UserController) UpdateUser(c *gin.Context) {
+func (ctl *UserController) UpdateUser(c *gin.Context) error {
 	id := c.Param("id")
 	var user entities.User
-	if err := ctl.DB.First(&user, id).Error; err != nil {
-		response.NotFound(c, "User not found", err, "[UpdateUser]")
-		return
+	if err := ctl.db(c).First(&user, id).Error; err != nil {
+		return userLookupError(err)
+	}
+
+	// Optimistic concurrency: If-Match harus berasal dari versi user saat ini (representasi apa pun)
+	if !response.IfMatch(c, userVersionETag(&user)) {
+		response.PreconditionFailed(c, "errors."+string(response.CodeResourceModified), response.WithCode(response.CodeResourceModified, nil), "[UpdateUser]")
+		return nil
 	}
 
 	var input struct {
 		Name     *string `json:"name"`
 		Email    *string `json:"email"`
 		Password *string `json:"password"`
+		Locale   *string `json:"locale"`
 	}
 
 	// Validate input
 	if err := c.ShouldBindJSON(&input); err != nil {
-		response.BadRequest(c, "Invalid input", err, "[UpdateUser]")
-		return
+		return err
 	}
 
 	// Check if email already exists and different from current email
 	if input.Email != nil && *input.Email != user.Email {
 		var count int64
-		ctl.DB.Model(&entities.User{}).Where("email = ?", *input.Email).Count(&count)
+		ctl.db(c).Model(&entities.User{}).Where("email = ?", *input.Email).Count(&count)
 		if count > 0 {
-			response.UnprocessableEntity(c, "Email already in use", nil, "[UpdateUser]")
-			return
+			return response.ErrorFromCode(response.CodeUserEmailTaken, nil)
 		}
 	}
 
@@ -164,58 +201,111 @@ func (ctl *UserController) UpdateUser(c *gin.Context) {
 		hashed, _ := helper.HashPassword(*input.Password)
 		user.Password = hashed
 	}
+	if input.Locale != nil {
+		locale, ok := i18n.Supported(*input.Locale)
+		if !ok {
+			return response.ValidationError("Validation failed", map[string]interface{}{
+				"locale": []string{"Supported locales: " + strings.Join(i18n.Locales(), ", ")},
+			})
+		}
+		user.Locale = &locale
+	}
 	user.UpdatedAt = time.Now()
 
-	if err := ctl.DB.Save(&user).Error; err != nil {
-		response.InternalServerError(c, "Failed to update user", err, "[UpdateUser]")
-		return
+	// Dengan If-Match, update hanya berlaku jika version belum berubah sejak dibaca di atas;
+	// cek & tulis dalam satu UPDATE agar dua request paralel tidak saling menimpa.
+	query := ctl.db(c).Model(&entities.User{}).Where("id = ?", user.ID)
+	if c.GetHeader("If-Match") != "" {
+		query = query.Where("version = ?", user.Version)
+	}
+	result := query.Updates(map[string]interface{}{
+		"name":       user.Name,
+		"email":      user.Email,
+		"password":   user.Password,
+		"locale":     user.Locale,
+		"updated_at": user.UpdatedAt,
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		response.PreconditionFailed(c, "errors."+string(response.CodeResourceModified), response.WithCode(response.CodeResourceModified, nil), "[UpdateUser]")
+		return nil
 	}
+	user.Version++
 
-	response.Success(c, "User updated successfully", user)
+	response.SetETag(c, userETag(c, &user))
+	response.Success(c, "User updated successfully", responses.UserToResponse(&user))
+	return nil
 }
 
-func (ctl *UserController) DeleteUser(c *gin.Context) {
+func (ctl *UserController) DeleteUser(c *gin.Context) error {
 	id := c.Param("id")
 	var user entities.User
-	if err := ctl.DB.First(&user, id).Error; err != nil {
-		response.NotFound(c, "User not found", err, "[UpdateUser]")
-		return
+	if err := ctl.db(c).First(&user, id).Error; err != nil {
+		return userLookupError(err)
 	}
-	if err := ctl.DB.Delete(&user, id).Error; err != nil {
-		response.InternalServerError(c, "Failed to delete user", err, "[DeleteUser]")
-		return
+	if err := ctl.db(c).Delete(&user, id).Error; err != nil {
+		return response.NewError(500, response.CodeInternalError, "Failed to delete user", err)
 	}
 	response.Success(c, "User deleted successfully", nil)
+	return nil
+}
+
+// db mengikat query ke context request, sehingga query dibatalkan saat timeout / client disconnect
+func (ctl *UserController) db(c *gin.Context) *gorm.DB {
+	return ctl.DB.WithContext(c.Request.Context())
+}
+
+// userETag adalah ETag versi user (ID + kolom version) untuk representasi yang diminta.
+// Kolom version (bukan updated_at yang presisinya detik) agar dua update dalam detik yang sama
+// tetap menghasilkan ETag berbeda.
+func userETag(c *gin.Context, user *entities.User) string {
+	return response.RepresentationETag(c, "user", user.ID, user.Version)
+}
+
+// userVersionETag adalah validator If-Match: hanya ID + version, tanpa representasi
+func userVersionETag(user *entities.User) string {
+	return response.StrongETag("user", user.ID, user.Version)
+}
+
+// userLookupError memberi code USER_NOT_FOUND untuk record not found, error lain diteruskan apa adanya
+func userLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.ErrorFromCode(response.CodeUserNotFound, err)
+	}
+	return err
 }
 
 // sengaja error
 func (ctl *UserController) ErrorDebug(c *gin.Context) {
 	// Simulate an error
-	services.AppLogger.Debug("Debug test message", nil)
+	services.AppLogger.WithContext(c).Debug("Debug test message", nil)
 	response.Success(c, "Debug test message", nil)
 }
 
 func (ctl *UserController) ErrorInfo(c *gin.Context) {
 	// Simulate an error
-	services.AppLogger.Info("Info test message", nil)
+	services.AppLogger.WithContext(c).Info("Info test message", nil)
 	response.Success(c, "Info test message", nil)
 }
 
 func (ctl *UserController) ErrorWarn(c *gin.Context) {
 	// Simulate an error
-	services.AppLogger.Warn("Warning test message", nil)
+	services.AppLogger.WithContext(c).Warn("Warning test message", nil)
 	response.Success(c, "Warning test message", nil)
 }
 
 func (ctl *UserController) ErrorError(c *gin.Context) {
 	// Simulate an error
-	services.AppLogger.Error("Error test message", nil, nil)
+	services.AppLogger.WithContext(c).Error("Error test message", nil, nil)
 	response.Success(c, "Error test message", nil)
 }
 
 func (ctl *UserController) ErrorCritical(c *gin.Context) {
 	// Simulate an error
-	services.AppLogger.Critical("Critical test message", nil, nil)
+	services.AppLogger.WithContext(c).Critical("Critical test message", nil, nil)
 	response.Success(c, "Critical test message", nil)
 }
 
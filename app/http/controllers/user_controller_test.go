@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"response-std/app/models/entities"
+	"response-std/app/pkg/response"
+)
+
+func newUserTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, entities.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entities.User{}, &entities.Roles{}); err != nil {
+		t.Fatal(err)
+	}
+	user := entities.User{Name: "alice", Email: "alice@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctl := NewUserController(db, nil)
+	r := gin.New()
+	// Sama seperti middleware.ErrorResponseMiddleware
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			response.FromError(c, c.Errors.Last().Err)
+		}
+	})
+	r.GET("/users/:id", response.Handle(ctl.GetUserByID))
+	r.PUT("/users/:id", response.Handle(ctl.UpdateUser))
+	return r, db, user
+}
+
+func serve(r *gin.Engine, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateUserIfMatch(t *testing.T) {
+	r, _, user := newUserTestRouter(t)
+	target := "/users/" + strconv.FormatUint(uint64(user.ID), 10)
+
+	etag := serve(r, http.MethodGet, target, "", nil).Header().Get("ETag")
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("GET etag = %q, want strong etag", etag)
+	}
+
+	w := serve(r, http.MethodPut, target, `{"name":"bob"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusOK {
+		t.Fatalf("first update = %d %s, want 200", w.Code, w.Body)
+	}
+	updated := w.Header().Get("ETag")
+	if updated == etag {
+		t.Error("update within the same second must change the etag")
+	}
+	if got := serve(r, http.MethodGet, target, "", nil).Header().Get("ETag"); got != updated {
+		t.Errorf("GET after update etag = %q, want %q", got, updated)
+	}
+
+	w = serve(r, http.MethodPut, target, `{"name":"carol"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale If-Match = %d, want 412", w.Code)
+	}
+
+	w = serve(r, http.MethodPut, target, `{"name":"carol"}`, map[string]string{"If-Match": "W/" + updated})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("weak If-Match = %d, want 412 (strong comparison)", w.Code)
+	}
+}
+
+func TestUpdateUserIfMatchAnyRepresentation(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		header map[string]string
+	}{
+		{"fields", "?fields=name", nil},
+		{"include", "?include=roles", nil},
+		{"xml", "", map[string]string{"Accept": "application/xml"}},
+		{"locale", "", map[string]string{"Accept-Language": "en"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _, user := newUserTestRouter(t)
+			target := "/users/" + strconv.FormatUint(uint64(user.ID), 10)
+
+			get := serve(r, http.MethodGet, target+tc.query, "", tc.header)
+			etag := get.Header().Get("ETag")
+			if get.Code != http.StatusOK || etag == "" {
+				t.Fatalf("GET = %d %q, want 200 with etag", get.Code, etag)
+			}
+			if plain := serve(r, http.MethodGet, target, "", nil).Header().Get("ETag"); plain == etag {
+				t.Errorf("representation etag should differ from the plain GET etag")
+			}
+
+			w := serve(r, http.MethodPut, target, `{"name":"bob"}`, map[string]string{"If-Match": etag})
+			if w.Code != http.StatusOK {
+				t.Fatalf("update = %d %s, want 200", w.Code, w.Body)
+			}
+			w = serve(r, http.MethodPut, target, `{"name":"carol"}`, map[string]string{"If-Match": etag})
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("stale If-Match = %d, want 412", w.Code)
+			}
+		})
+	}
+}
+
+func TestUpdateUserConcurrentModification(t *testing.T) {
+	r, db, user := newUserTestRouter(t)
+	target := "/users/" + strconv.FormatUint(uint64(user.ID), 10)
+	etag := serve(r, http.MethodGet, target, "", nil).Header().Get("ETag")
+
+	// Simulasikan request lain yang meng-update user di antara baca (If-Match lolos) dan tulis
+	bumped := false
+	db.Callback().Update().Before("gorm:update").Register("test:concurrent_update", func(tx *gorm.DB) {
+		if bumped {
+			return
+		}
+		bumped = true
+		tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+			Exec("UPDATE users SET name = ?, version = version + 1 WHERE id = ?", "mallory", user.ID)
+	})
+
+	w := serve(r, http.MethodPut, target, `{"name":"bob"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("update = %d %s, want 412", w.Code, w.Body)
+	}
+
+	var stored entities.User
+	db.First(&stored, user.ID)
+	if stored.Name != "mallory" {
+		t.Errorf("name = %q, concurrent update must not be overwritten", stored.Name)
+	}
+}
@@ -1,76 +1,683 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"response-std/app/helpers/helper"
+	"response-std/app/models/entities"
+	"response-std/app/pkg/idempotency"
+	"response-std/app/pkg/ratelimit"
 	"response-std/app/pkg/response"
+	"response-std/config"
 	"response-std/libs/external/services"
+	"response-std/libs/requestid"
+	"response-std/libs/router"
 
-	// "github.com/gin-contrib/cors"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
 // ---------------------------
 // CORS MIDDLEWARE
 // ---------------------------
+// CORSMiddleware membaca policy dari config (CORS_*), dengan override per API version
+// (CORS_<VERSION>_*). Version ditentukan dari path lewat router registry.
+// Handler untuk setiap version di router.RouteRegistry dibuat sekali di sini, sehingga config
+// yang tidak valid (mis. origin tanpa scheme) membuat aplikasi panic saat boot, bukan per request.
 func CORSMiddleware() gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With"}
-	config.ExposeHeaders = []string{"Content-Length"}
-	config.AllowCredentials = true
-	config.MaxAge = 12 * time.Hour
+	handlers := map[string]gin.HandlerFunc{"": newCORSHandler("")}
+	for version := range router.RouteRegistry {
+		handlers[version] = newCORSHandler(version)
+	}
 
-	return cors.New(config)
+	return func(c *gin.Context) {
+		h, ok := handlers[router.VersionForPath(c.Request.URL.Path)]
+		if !ok {
+			h = handlers[""]
+		}
+		h(c)
+	}
+}
+
+func newCORSHandler(version string) gin.HandlerFunc {
+	policy := config.ENV.CORS(version)
+	// Allow-all memantulkan Origin apa pun; bersama credentials, situs mana pun bisa membaca
+	// respons atas nama user yang login. Ditolak di production.
+	if policy.AllowsAnyOrigin() && policy.AllowCredentials && config.ENV.Environment == "production" {
+		panic(fmt.Sprintf("Invalid CORS configuration (version %q): CORS_ALLOW_ALL cannot be combined with CORS_ALLOW_CREDENTIALS in production", version))
+	}
+
+	corsCfg := corsConfig(policy)
+	if err := corsCfg.Validate(); err != nil {
+		panic(fmt.Sprintf("Invalid CORS configuration (version %q): %v", version, err))
+	}
+	return cors.New(corsCfg)
+}
+
+func corsConfig(policy config.CORSConfig) cors.Config {
+	corsCfg := cors.DefaultConfig()
+	corsCfg.AllowWildcard = true // https://*.example.com
+	corsCfg.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+	corsCfg.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With", requestid.Header, idempotency.Header, "If-Match", "If-None-Match"}
+	corsCfg.ExposeHeaders = []string{"Content-Length", requestid.Header, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", idempotency.ReplayedHeader, "ETag"}
+	corsCfg.AllowCredentials = policy.AllowCredentials
+	corsCfg.MaxAge = 12 * time.Hour
+
+	if len(policy.AllowedMethods) > 0 {
+		corsCfg.AllowMethods = policy.AllowedMethods
+	}
+	if len(policy.AllowedHeaders) > 0 {
+		corsCfg.AllowHeaders = policy.AllowedHeaders
+	}
+	if len(policy.ExposedHeaders) > 0 {
+		corsCfg.ExposeHeaders = policy.ExposedHeaders
+	}
+	if policy.MaxAge > 0 {
+		corsCfg.MaxAge = policy.MaxAge
+	}
+
+	var origins []string
+	for _, origin := range policy.AllowedOrigins {
+		if origin == "*" {
+			policy.AllowAll = true
+			continue
+		}
+		origins = append(origins, origin)
+	}
+
+	switch {
+	case policy.AllowAll:
+		// Origin dipantulkan (bukan "*"); kombinasi dengan AllowCredentials ditolak di production (newCORSHandler)
+		corsCfg.AllowOriginFunc = func(string) bool { return true }
+	case len(origins) > 0:
+		corsCfg.AllowOrigins = origins
+	default:
+		// Tidak ada origin yang diizinkan: semua request cross-origin ditolak
+		corsCfg.AllowOriginFunc = func(string) bool { return false }
+	}
+
+	return corsCfg
+}
+
+// ---------------------------
+// SECURITY HEADERS MIDDLEWARE
+// ---------------------------
+// SecurityHeadersMiddleware mengirim HSTS, X-Content-Type-Options, X-Frame-Options, Referrer-Policy,
+// Permissions-Policy dan Content-Security-Policy sesuai config.SecurityHeadersPolicy().
+// SECURITY_HEADERS=false mematikan semuanya.
+func SecurityHeadersMiddleware() gin.HandlerFunc {
+	if !config.ENV.SecurityHeaders {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	policy := config.ENV.SecurityHeadersPolicy()
+	cspHeader := "Content-Security-Policy"
+	if policy.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	headers := map[string]string{
+		"Strict-Transport-Security": policy.HSTS,
+		"X-Content-Type-Options":    policy.ContentTypeOptions,
+		"X-Frame-Options":           policy.FrameOptions,
+		"Referrer-Policy":           policy.ReferrerPolicy,
+		"Permissions-Policy":        policy.PermissionsPolicy,
+		cspHeader:                   policy.CSP,
+	}
+	for name, value := range headers {
+		if value == "" {
+			delete(headers, name)
+		}
+	}
+
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		c.Next()
+	}
+}
+
+// ---------------------------
+// REQUEST ID MIDDLEWARE
+// ---------------------------
+// RequestIDMiddleware memakai X-Request-ID dari klien (jika valid) atau membuat yang baru,
+// lalu menyimpannya di gin context & request context dan mengembalikannya di header respons.
+// Dipasang paling awal agar semua log, envelope dan outbound call membawa ID yang sama.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set(requestid.ContextKey, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
+
+// ---------------------------
+// RESPONSE META MIDDLEWARE
+// ---------------------------
+// ResponseMetaMiddleware mengisi blok "meta" envelope (processing_time, api_version, request_id,
+// deprecation) jika RESPONSE_META=true. Version diambil dari router.RouteRegistry lewat router.Setup.
+// Header Deprecation/Sunset selalu dikirim untuk version yang ada di DEPRECATED_API_VERSIONS.
+func ResponseMetaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := router.VersionOf(c.Request.Method, c.FullPath())
+
+		if d, deprecated := router.DeprecationOf(version); deprecated {
+			c.Header("Deprecation", "true")
+			if d.Sunset != nil {
+				c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+
+		if config.ENV != nil && config.ENV.ResponseMeta {
+			response.StartMeta(c, version)
+		}
+
+		c.Next()
+	}
+}
+
+// ---------------------------
+// TIMEOUT MIDDLEWARE
+// ---------------------------
+const timeoutKey = "middleware.timeout"
+
+type requestTimeout struct {
+	parent context.Context // context sebelum deadline dipasang, dipakai ulang saat override
+	cancel context.CancelFunc
+}
+
+// TimeoutMiddleware memasang deadline HANDLER_TIMEOUT pada c.Request.Context(), atau durasi dari
+// HANDLER_TIMEOUT_ROUTES jika route cocok. Query GORM yang memakai context request ikut dibatalkan;
+// jika deadline lewat dan handler belum menulis respons, klien mendapat 504 HANDLER_TIMEOUT.
+// Timeout bersifat kooperatif: handler yang tidak memakai context tetap berjalan sampai selesai.
+func TimeoutMiddleware() gin.HandlerFunc {
+	overrides := make(map[string]time.Duration, len(config.ENV.HandlerTimeoutRoutes))
+	for _, entry := range config.ENV.HandlerTimeoutRoutes {
+		route, raw, found := strings.Cut(entry, "=")
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if !found || err != nil {
+			panic("Invalid HANDLER_TIMEOUT_ROUTES entry: " + entry)
+		}
+		overrides[strings.TrimSpace(route)] = d
+	}
+
+	return func(c *gin.Context) {
+		timeout := config.ENV.HandlerTimeout
+		if d, ok := overrides[c.Request.Method+" "+c.FullPath()]; ok {
+			timeout = d
+		} else if d, ok := overrides[c.FullPath()]; ok {
+			timeout = d
+		}
+		withTimeout(c, timeout)
+	}
+}
+
+// Timeout menimpa deadline untuk satu route / group (lebih pendek atau lebih panjang dari default).
+// Durasi 0 mematikan timeout.
+// contoh penggunaan:
+// api.POST("/reports", middleware.Timeout(2*time.Minute), reportController.Generate)
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		withTimeout(c, d)
+	}
+}
+
+func withTimeout(c *gin.Context, d time.Duration) {
+	// Deadline dari middleware sebelumnya dibatalkan & diganti, bukan ditumpuk,
+	// karena context turunan tidak bisa memperpanjang deadline induknya
+	parent := c.Request.Context()
+	if value, exists := c.Get(timeoutKey); exists {
+		previous := value.(*requestTimeout)
+		previous.cancel()
+		parent = previous.parent
+	}
+
+	if d <= 0 {
+		c.Set(timeoutKey, &requestTimeout{parent: parent, cancel: func() {}})
+		c.Request = c.Request.WithContext(parent)
+		c.Next()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(parent, d)
+	defer cancel()
+
+	c.Set(timeoutKey, &requestTimeout{parent: parent, cancel: cancel})
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+		response.GatewayTimeout(c, "errors."+string(response.CodeHandlerTimeout), response.WithCode(response.CodeHandlerTimeout, ctx.Err()), "[Timeout Middleware]")
+		c.Abort()
+	}
+}
+
+// ---------------------------
+// BODY LIMIT MIDDLEWARE
+// ---------------------------
+// BodyLimitMiddleware membatasi ukuran body request: BODY_LIMIT untuk semua route, atau override
+// per route group dari BODY_LIMIT_ROUTES (prefix path terpanjang menang). Body terlalu besar -> 413.
+func BodyLimitMiddleware() gin.HandlerFunc {
+	defaultLimit := mustParseSize("BODY_LIMIT", config.ENV.BodyLimit)
+
+	type routeLimit struct {
+		prefix string
+		limit  int64
+	}
+	var overrides []routeLimit
+	for _, entry := range config.ENV.BodyLimitRoutes {
+		prefix, raw, found := strings.Cut(entry, "=")
+		if !found {
+			panic("Invalid BODY_LIMIT_ROUTES entry: " + entry)
+		}
+		overrides = append(overrides, routeLimit{strings.TrimSpace(prefix), mustParseSize("BODY_LIMIT_ROUTES", raw)})
+	}
+
+	return func(c *gin.Context) {
+		limit, longest := defaultLimit, ""
+		for _, o := range overrides {
+			if strings.HasPrefix(c.Request.URL.Path, o.prefix) && len(o.prefix) > len(longest) {
+				limit, longest = o.limit, o.prefix
+			}
+		}
+		limitBody(c, limit)
+	}
+}
+
+// BodyLimit membatasi body untuk satu route / group (hanya bisa lebih ketat dari BodyLimitMiddleware)
+// contoh penggunaan:
+// api.POST("/import", middleware.BodyLimit(5<<20), importController.Import)
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limitBody(c, limit)
+	}
+}
+
+func limitBody(c *gin.Context, limit int64) {
+	if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		c.Next()
+		return
+	}
+
+	tooLarge := func() {
+		response.RequestEntityTooLarge(c, "errors."+string(response.CodeRequestTooLarge), response.WithCode(response.CodeRequestTooLarge, nil), "[Body Limit Middleware]")
+		c.Abort()
+	}
+
+	// Content-Length diketahui: tolak sebelum body dibaca (net/http menjamin body tidak melebihinya)
+	if c.Request.ContentLength > limit {
+		tooLarge()
+		return
+	}
+
+	// Chunked (tanpa Content-Length): baca maksimal limit+1 byte agar 413 dikirim sebelum handler jalan
+	if c.Request.ContentLength < 0 {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		if err != nil {
+			response.BadRequest(c, "Invalid request format", response.WithCode(response.CodeRequestMalformed, err), "[Body Limit Middleware]")
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > limit {
+			tooLarge()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+	}
+
+	c.Next()
+}
+
+func mustParseSize(name, raw string) int64 {
+	if strings.TrimSpace(raw) == "" {
+		return 0
+	}
+	size, err := helper.ParseByteSize(raw)
+	if err != nil {
+		panic("Invalid " + name + ": " + err.Error())
+	}
+	return size
 }
 
 // ---------------------------
 // LOGGING MIDDLEWARE
 // ---------------------------
+// LoggingMiddleware menulis access log terstruktur lewat services.Logger (file/console/Discord).
+// Path di ACCESS_LOG_EXCLUDE_PATHS tidak di-log, request biasa di-sample sesuai ACCESS_LOG_SAMPLE_RATE,
+// sedangkan error 5xx dan request di atas ACCESS_LOG_SLOW_THRESHOLD selalu di-log sebagai warning.
 func LoggingMiddleware(logger *services.Logger) gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	})
+	excluded := make(map[string]bool, len(config.ENV.AccessLogExcludePaths))
+	for _, path := range config.ENV.AccessLogExcludePaths {
+		excluded[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		if excluded[path] || excluded[c.FullPath()] {
+			return
+		}
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+		slow := config.ENV.AccessLogSlowThreshold > 0 && latency >= config.ENV.AccessLogSlowThreshold
+
+		if status < http.StatusInternalServerError && !slow && !sampled(config.ENV.AccessLogSampleRate) {
+			return
+		}
+
+		fields := map[string]interface{}{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(), // template, mis. /api/v1/users/:id ("" jika route tidak ditemukan)
+			"path":       path,
+			"status":     status,
+			"latency_ms": latency.Milliseconds(),
+			"bytes":      max(c.Writer.Size(), 0),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		}
+		if value, exists := c.Get("user"); exists {
+			if user, ok := value.(entities.User); ok {
+				fields["user_id"] = user.ID
+			}
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+
+		log := logger.WithContext(c)
+		switch {
+		case slow:
+			fields["slow_threshold_ms"] = config.ENV.AccessLogSlowThreshold.Milliseconds()
+			log.Warn("Slow request", fields)
+		case status >= http.StatusInternalServerError:
+			log.Warn("HTTP request - Server Error", fields)
+		default:
+			log.Info("HTTP request", fields)
+		}
+	}
+}
+
+// sampled true untuk sebagian request sesuai rate (0..1); rate >= 1 berarti semua
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
 }
 
 // ---------------------------
 // ERROR HANDLING MIDDLEWARE
 // ---------------------------
+// ErrorHandlingMiddleware me-recover panic dengan tipe apa pun (string, error, value lain),
+// mencatat stack trace lewat Logger.Critical (ikut ke Discord), lalu merespons 500 standar.
 func ErrorHandlingMiddleware(logger *services.Logger) gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			logger.Error("Panic recovered: "+err, nil, map[string]interface{}{})
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			stack := debug.Stack()
+
+			fields := map[string]interface{}{
+				"panic_type": fmt.Sprintf("%T", recovered),
+				"method":     c.Request.Method,
+				"route":      c.FullPath(),
+				"path":       c.Request.URL.Path,
+				"client_ip":  c.ClientIP(),
+				"stack":      string(stack),
+			}
+			if value, exists := c.Get("user"); exists {
+				if user, ok := value.(entities.User); ok {
+					fields["user_id"] = user.ID
+				}
+			}
+			logger.WithContext(c).Critical("Panic recovered: "+err.Error(), err, fields)
+
+			// Koneksi putus / respons sudah terkirim: tidak bisa menulis body lagi
+			if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			response.Panic(c, err, stack)
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// ---------------------------
+// ERROR FORMAT MIDDLEWARE
+// ---------------------------
+// ErrorFormatMiddleware memaksa format error untuk satu route group,
+// misal response.ErrorFormatProblem untuk klien yang memakai application/problem+json.
+// Klien yang mengirim Accept: application/problem+json selalu mendapat problem+json.
+func ErrorFormatMiddleware(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.SetErrorFormat(c, format)
+		c.Next()
+	}
+}
+
+// ---------------------------
+// ERROR RESPONSE MIDDLEWARE
+// ---------------------------
+// ErrorResponseMiddleware merender error terakhir di c.Errors (dari response.Handle / c.Error)
+// lewat response.FromError, selama handler belum menulis respons.
+func ErrorResponseMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
 		}
-		response.InternalServerError(c, "Internal server error occurred", nil, "[Error Handling Middleware]")
-	})
+
+		response.FromError(c, c.Errors.Last().Err, "[Error Response Middleware]")
+	}
 }
 
 // ---------------------------
 // RATE LIMITING MIDDLEWARE
 // ---------------------------
-var limiter = rate.NewLimiter(10, 20) // 10 requests per second, burst of 20
-
+// RateLimitMiddleware memakai policy "global" (per IP)
 func RateLimitMiddleware() gin.HandlerFunc {
+	return RateLimit(ratelimit.PolicyGlobal)
+}
+
+// RateLimit membatasi request dengan policy bernama (lihat ratelimit.Init / ratelimit.Register)
+// contoh penggunaan:
+// auth.POST("/login", middleware.RateLimit(ratelimit.PolicyLogin), authController.Login(config.DB))
+func RateLimit(policyName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := ratelimit.Lookup(policyName)
+		if !ok {
+			// Policy belum terdaftar: jangan blokir request
+			c.Next()
+			return
+		}
+
+		result, err := policy.Allow(c)
+		if err != nil {
+			services.AppLogger.WithContext(c).Warn("Rate limit store unavailable, request allowed", map[string]interface{}{
+				"policy": policy.Name,
+				"error":  err.Error(),
+			})
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter().Seconds()))))
+			response.TooManyRequests(c, "Rate limit exceeded", response.WithCode(response.CodeRateLimited, nil), "[Rate Limit Middleware: "+policy.Name+"]")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ---------------------------
+// IDEMPOTENCY MIDDLEWARE
+// ---------------------------
+// Idempotency menyimpan respons pertama untuk header Idempotency-Key (per user & route, selama
+// IDEMPOTENCY_TTL) dan me-replay respons itu untuk retry dengan key yang sama.
+// Key sama + payload beda -> 422, request pertama masih diproses -> 409.
+// Respons 5xx tidak disimpan agar klien bisa retry. Request tanpa header diteruskan apa adanya.
+// contoh penggunaan:
+// user.POST("/", middleware.Idempotency(), response.Handle(userController.CreateUser))
+func Idempotency() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			response.TooManyRequests(c, "Rate limit exceeded", nil, "[Rate Limit Middleware]")
+		key := strings.TrimSpace(c.GetHeader(idempotency.Header))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			response.BadRequest(c, "Idempotency-Key is too long", response.WithCode(response.CodeRequestMalformed, nil), "[Idempotency Middleware]")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.BadRequest(c, "Invalid request format", response.WithCode(response.CodeRequestMalformed, err), "[Idempotency Middleware]")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		store, ttl, lock := idempotency.Default()
+		storageKey := idempotency.StorageKey(c, key)
+		fingerprint := idempotency.Fingerprint(c, body)
+		ctx := c.Request.Context()
+
+		existing, reserved, err := store.Reserve(ctx, storageKey, fingerprint, lock)
+		if err != nil {
+			services.AppLogger.WithContext(c).Warn("Idempotency store unavailable, request processed without idempotency", map[string]interface{}{
+				"error": err.Error(),
+			})
+			c.Next()
+			return
+		}
+
+		if !reserved {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				response.Fail(c, response.CodeIdempotencyKeyReused, nil, "[Idempotency Middleware]")
+			case !existing.Completed():
+				c.Header("Retry-After", "1")
+				response.Fail(c, response.CodeIdempotencyInProgress, nil, "[Idempotency Middleware]")
+			default:
+				for name, values := range existing.Header {
+					for _, value := range values {
+						c.Writer.Header().Add(name, value)
+					}
+				}
+				c.Header(idempotency.ReplayedHeader, "true")
+				c.Data(existing.StatusCode, existing.Header.Get("Content-Type"), existing.Body)
+			}
 			c.Abort()
 			return
 		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// Handler panic / 5xx: lepas reservasi (tetap jalan saat panic, tanpa recover)
+			if !completed {
+				store.Release(context.WithoutCancel(ctx), storageKey)
+			}
+		}()
+
 		c.Next()
+
+		// Error dari response.Handle / c.Error dirender di sini (bukan menunggu ErrorResponseMiddleware
+		// di luar) agar envelope error final ikut tersimpan. 4xx disimpan & di-replay; 5xx tidak
+		// disimpan supaya klien boleh retry dengan key yang sama.
+		if !recorder.Written() && len(c.Errors) > 0 {
+			response.FromError(c, c.Errors.Last().Err, "[Idempotency Middleware]")
+		}
+
+		status := recorder.Status()
+		if !recorder.Written() || status >= http.StatusInternalServerError {
+			return
+		}
+
+		header := make(http.Header)
+		for _, name := range idempotency.ReplayHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+
+		err = store.Complete(context.WithoutCancel(ctx), storageKey, idempotency.Record{
+			Fingerprint: fingerprint,
+			StatusCode:  status,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+			ExpiresAt:   time.Now().Add(ttl),
+		})
+		if err != nil {
+			services.AppLogger.WithContext(c).Warn("Failed to store idempotent response", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		completed = true
 	}
 }
+
+// bodyRecorder menyalin body respons sambil tetap menulis ke klien
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
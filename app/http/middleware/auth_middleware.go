@@ -3,13 +3,17 @@ package middleware
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"response-std/app/models/entities"
+	"response-std/app/pkg/auth"
 	"response-std/app/pkg/response"
+	"response-std/config"
+	"response-std/libs/external/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -24,7 +28,7 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 
 		// Check if Authorization header exists and has Bearer token
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			response.Unauthorized(c, "Authorization header required", nil, "[Auth Middleware]")
+			response.Unauthorized(c, "Authorization header required", response.WithCode(response.CodeAuthTokenMissing, nil), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
@@ -32,10 +36,22 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		// Extract token from header
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+		// AUTH_GUARD=jwt|both: JWT diverifikasi tanpa query database
+		mode := auth.GuardMode()
+		if guard := auth.DefaultJWT(); guard != nil && mode != auth.GuardToken && auth.LooksLikeJWT(tokenString) {
+			authenticateJWT(c, guard, tokenString)
+			return
+		}
+		if mode == auth.GuardJWT {
+			response.Unauthorized(c, "Invalid token format", response.WithCode(response.CodeAuthTokenInvalid, nil), "[Auth Middleware]")
+			c.Abort()
+			return
+		}
+
 		// Parse token format: ID|plain_token
 		parts := strings.SplitN(tokenString, "|", 2)
 		if len(parts) != 2 {
-			response.Unauthorized(c, "Invalid token format", nil, "[Auth Middleware]")
+			response.Unauthorized(c, "Invalid token format", response.WithCode(response.CodeAuthTokenInvalid, nil), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
@@ -45,7 +61,7 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		// Convert token ID to integer
 		id, err := strconv.Atoi(tokenID)
 		if err != nil {
-			response.Unauthorized(c, "Invalid token ID", err, "[Auth Middleware]")
+			response.Unauthorized(c, "Invalid token ID", response.WithCode(response.CodeAuthTokenInvalid, err), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
@@ -55,10 +71,11 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		hashedTokenHex := hex.EncodeToString(hashedToken[:])
 
 		// Find token in database
+		db := db.WithContext(c.Request.Context())
 		var token entities.PersonalAccessTokens
 		err = db.Where("id = ? AND token = ?", id, hashedTokenHex).First(&token).Error
 		if err != nil {
-			response.Unauthorized(c, "Invalid or expired token", err, "[Auth Middleware]")
+			response.Unauthorized(c, "Invalid or expired token", response.WithCode(response.CodeAuthTokenInvalid, err), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
@@ -67,7 +84,7 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		if token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now()) {
 			// Delete expired token
 			db.Delete(&token)
-			response.Unauthorized(c, "Token has expired", nil, "[Auth Middleware]")
+			response.Unauthorized(c, "Token has expired", response.WithCode(response.CodeAuthTokenExpired, nil), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
@@ -77,19 +94,105 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		err = db.Preload("Roles.Permissions").Preload("Permissions").
 			Where("id = ?", token.TokenableID).First(&user).Error
 		if err != nil {
-			response.Unauthorized(c, "User not found", err, "[Auth Middleware]")
+			response.Unauthorized(c, "User not found", response.WithCode(response.CodeAuthTokenInvalid, err), "[Auth Middleware]")
 			c.Abort()
 			return
 		}
 
+		// Catat pemakaian token (debounce TOKEN_LAST_USED_INTERVAL); gagal update tidak menolak request
+		interval := time.Minute
+		if config.ENV != nil {
+			interval = config.ENV.TokenLastUsedInterval
+		}
+		if err := auth.TouchToken(db, &token, auth.ClientFromRequest(c), interval); err != nil && services.AppLogger != nil {
+			services.AppLogger.WithContext(c).Warn("Failed to update token last_used_at", map[string]interface{}{
+				"token_id": token.ID,
+				"error":    err.Error(),
+			})
+		}
+
 		// Store user in context for use in handlers
 		c.Set("user", user)
 		c.Set("token", token)
+		c.Set("abilities", auth.ParseAbilities(token.Abilities))
+
+		c.Next()
+	}
+}
+
+// authenticateJWT memverifikasi JWT dan mengisi context dari claims (user, abilities, jwt_claims)
+func authenticateJWT(c *gin.Context, guard *auth.JWTGuard, tokenString string) {
+	claims, err := guard.Parse(c.Request.Context(), tokenString)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrJWTExpired):
+			response.Unauthorized(c, "Token has expired", response.WithCode(response.CodeAuthTokenExpired, err), "[Auth Middleware]")
+		case errors.Is(err, auth.ErrJWTInvalid), errors.Is(err, auth.ErrJWTRevoked):
+			response.Unauthorized(c, "Invalid or expired token", response.WithCode(response.CodeAuthTokenInvalid, err), "[Auth Middleware]")
+		default:
+			// Denylist (Redis) tidak bisa dibaca: tolak daripada menerima token yang mungkin sudah dicabut
+			response.ServiceUnavailable(c, "Authentication service unavailable", err, "[Auth Middleware]")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("user", auth.UserFromClaims(claims))
+	c.Set("abilities", claims.Abilities)
+	c.Set("jwt_claims", *claims)
+
+	c.Next()
+}
+
+// ---------------------------
+// TOKEN ABILITY MIDDLEWARE (Sanctum-style scopes)
+// ---------------------------
+// TokenCan mewajibkan token aktif memiliki SEMUA ability yang disebut.
+// Dipakai setelah AuthMiddleware dan bisa digabung dengan RoleMiddleware / PermissionMiddleware:
+// role/permission membatasi user, ability membatasi token yang dipakai user tersebut.
+func TokenCan(abilities ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("abilities"); !exists {
+			response.Unauthorized(c, "User not authenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[TokenCan Middleware]")
+			c.Abort()
+			return
+		}
+
+		granted := auth.TokenAbilities(c)
+		for _, ability := range abilities {
+			if granted.Cant(ability) {
+				response.Forbidden(c, fmt.Sprintf("Token ability denied. Required ability: %s", ability), response.WithCode(response.CodeAuthAbilityRequired, nil), "[TokenCan Middleware]")
+				c.Abort()
+				return
+			}
+		}
 
 		c.Next()
 	}
 }
 
+// TokenCanAny mewajibkan token aktif memiliki SALAH SATU ability yang disebut
+func TokenCanAny(abilities ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("abilities"); !exists {
+			response.Unauthorized(c, "User not authenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[TokenCanAny Middleware]")
+			c.Abort()
+			return
+		}
+
+		granted := auth.TokenAbilities(c)
+		for _, ability := range abilities {
+			if granted.Can(ability) {
+				c.Next()
+				return
+			}
+		}
+
+		response.Forbidden(c, fmt.Sprintf("Token ability denied. Required one of: %s", strings.Join(abilities, ", ")), response.WithCode(response.CodeAuthAbilityRequired, nil), "[TokenCanAny Middleware]")
+		c.Abort()
+	}
+}
+
 // ---------------------------
 // ROLE MIDDLEWARE (Role-based Access Control)
 // ---------------------------
@@ -98,7 +201,7 @@ func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 		// Get user from context (set by AuthMiddleware)
 		userInterface, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "User not authenticated", nil, "[Role Middleware]")
+			response.Unauthorized(c, "User not authenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[Role Middleware]")
 			c.Abort()
 			return
 		}
@@ -120,7 +223,7 @@ func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 		}
 
 		if !hasRole {
-			response.Forbidden(c, fmt.Sprintf("Access denied. Required role: %s", requiredRole), nil, "[Role Middleware]")
+			response.Forbidden(c, fmt.Sprintf("Access denied. Required role: %s", requiredRole), response.WithCode(response.CodeAuthRoleRequired, nil), "[Role Middleware]")
 			c.Abort()
 			return
 		}
@@ -137,7 +240,7 @@ func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
 		// Get user from context
 		userInterface, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "User not authenticated", nil, "[Permission Middleware]")
+			response.Unauthorized(c, "User not authenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[Permission Middleware]")
 			c.Abort()
 			return
 		}
@@ -174,7 +277,7 @@ func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
 		}
 
 		if !hasPermission {
-			response.Forbidden(c, fmt.Sprintf("Access denied. Required permission: %s", requiredPermission), nil, "[Permission Middleware]")
+			response.Forbidden(c, fmt.Sprintf("Access denied. Required permission: %s", requiredPermission), response.WithCode(response.CodeAuthPermissionRequired, nil), "[Permission Middleware]")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"response-std/config"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content-Type yang dikompres jika COMPRESSION_TYPES kosong
+var defaultCompressionTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/problem+xml",
+	"text/csv",
+	"text/plain",
+	"text/html",
+}
+
+var gzipPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// ---------------------------
+// COMPRESSION MIDDLEWARE
+// ---------------------------
+// CompressionMiddleware mengompres respons dengan brotli atau gzip sesuai Accept-Encoding.
+// Respons di bawah COMPRESSION_MIN_SIZE atau dengan Content-Type di luar COMPRESSION_TYPES
+// dikirim apa adanya. COMPRESSION=false mematikan middleware ini.
+func CompressionMiddleware() gin.HandlerFunc {
+	if !config.ENV.Compression {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	minSize := int(mustParseSize("COMPRESSION_MIN_SIZE", config.ENV.CompressionMinSize))
+	types := make(map[string]bool)
+	list := config.ENV.CompressionTypes
+	if len(list) == 0 {
+		list = defaultCompressionTypes
+	}
+	for _, t := range list {
+		types[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize, types: types}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding memilih "br" atau "gzip" dari Accept-Encoding (q tertinggi, br menang jika seri)
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name != "br" && name != "gzip" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > bestQ || (q == bestQ && name == "br") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter menahan body sampai minSize tercapai, lalu memutuskan dikompres atau tidak
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	types    map[string]bool
+
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Written true juga saat body masih ditahan di buffer
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.minSize)
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decide(false)
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide memasang encoder jika respons layak dikompres, lalu menulis isi buffer
+func (w *compressWriter) decide(bigEnough bool) error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	if bigEnough && w.compressible() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+
+		switch w.encoding {
+		case "br":
+			w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		default:
+			gz := gzipPool.Get().(*gzip.Writer)
+			gz.Reset(w.ResponseWriter)
+			w.encoder = gz
+		}
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return w.types[mediaType]
+}
+
+func (w *compressWriter) finish() {
+	w.decide(w.buf.Len() >= w.minSize)
+	if w.encoder == nil {
+		return
+	}
+
+	w.encoder.Close()
+	if gz, ok := w.encoder.(*gzip.Writer); ok {
+		gzipPool.Put(gz)
+	}
+	w.encoder = nil
+}
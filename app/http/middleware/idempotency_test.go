@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"response-std/app/pkg/idempotency"
+	"response-std/app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newIdempotencyRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	idempotency.SetStore(idempotency.NewMemoryStore())
+
+	r := gin.New()
+	r.Use(ErrorResponseMiddleware())
+	r.POST("/items", Idempotency(), handler)
+	return r
+}
+
+func postItem(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotency.Header, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysSuccess(t *testing.T) {
+	calls := 0
+	r := newIdempotencyRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := postItem(r, "k1", `{"name":"a"}`)
+	second := postItem(r, "k1", `{"name":"a"}`)
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("missing %s header on replay", idempotency.ReplayedHeader)
+	}
+}
+
+func TestIdempotencyStoresErrorEnvelope(t *testing.T) {
+	calls := 0
+	r := newIdempotencyRouter(response.Handle(func(c *gin.Context) error {
+		calls++
+		return response.ErrorFromCode(response.CodeRequestMalformed, nil)
+	}))
+
+	first := postItem(r, "k2", `{}`)
+	second := postItem(r, "k2", `{}`)
+
+	if first.Code != http.StatusBadRequest || first.Body.Len() == 0 {
+		t.Fatalf("first = %d %q, want rendered 400 envelope", first.Code, first.Body)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1 (error response should be replayed)", calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	calls := 0
+	r := newIdempotencyRouter(response.Handle(func(c *gin.Context) error {
+		calls++
+		return response.ErrorFromCode(response.CodeInternalError, nil)
+	}))
+
+	postItem(r, "k3", `{}`)
+	postItem(r, "k3", `{}`)
+
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2 (5xx must stay retryable)", calls)
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	r := newIdempotencyRouter(func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	postItem(r, "k4", `{"name":"a"}`)
+	w := postItem(r, "k4", `{"name":"b"}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", w.Code)
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := newIdempotencyRouter(func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postItem(r, "k5", `{"name":"a"}`) }()
+	<-started
+
+	w := postItem(r, "k5", `{"name":"a"}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("concurrent retry = %d (Retry-After %q), want 409 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	w = postItem(r, "k5", `{"name":"b"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different payload while in flight = %d, want 422", w.Code)
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first = %d, want 201", first.Code)
+	}
+}
@@ -3,8 +3,11 @@ package auth
 
 import (
 	"fmt"
+	authpkg "response-std/app/pkg/auth"
+	"response-std/app/pkg/i18n"
 	"response-std/app/pkg/response"
 	"response-std/libs/external/services"
+	"strings"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/gin-gonic/gin"
@@ -14,6 +17,8 @@ import (
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Abilities (scope) yang diminta untuk token, kosong = ["*"]
+	Abilities []string `json:"abilities"`
 }
 
 // Validate validates the login request
@@ -24,17 +29,17 @@ func (r *LoginRequest) Validate(c *gin.Context) bool {
 		"password": []string{"required", "min:8"},
 	}
 
-	// Custom messages (optional, mirip Laravel)
-	messages := govalidator.MapData{
+	// Custom messages (optional, mirip Laravel), berisi translation key dari katalog i18n
+	messages := i18n.Messages(c, govalidator.MapData{
 		"username": []string{
-			"required:Username wajib diisi",
-			"min:Username minimal 3 karakter",
+			"required:validation.username.required",
+			"min:validation.username.min",
 		},
 		"password": []string{
-			"required:Password wajib diisi",
-			"min:Password minimal 8 karakter",
+			"required:validation.password.required",
+			"min:validation.password.min",
 		},
-	}
+	})
 
 	// Create validator options
 	opts := govalidator.Options{
@@ -58,21 +63,35 @@ func (r *LoginRequest) Validate(c *gin.Context) bool {
 		for k, v := range errors {
 			errorInterface[k] = v
 		}
-		services.AppLogger.Debug("Validation failed", errorInterface)
+		services.AppLogger.WithContext(c).Debug("Validation failed", errorInterface)
 
 		err := fmt.Errorf("%v", errorInterface)
-		response.UnprocessableValidation(c, "Validation failed", err, errorInterface, "[LoginRequest.Validate]")
+		response.UnprocessableValidation(c, "validation.failed", err, errorInterface, "[LoginRequest.Validate]")
 		spew.Dump(errors, "Validation errors", "\n errors from validation", errorInterface)
 		return false
 	}
 
+	// Custom validation: format abilities
+	for _, ability := range r.Abilities {
+		if !authpkg.ValidAbility(strings.ToLower(strings.TrimSpace(ability))) {
+			errorInterface := map[string]interface{}{
+				"abilities": []string{i18n.T(c, "validation.abilities.invalid", ability)},
+			}
+			services.AppLogger.WithContext(c).Debug("Validation failed", errorInterface)
+
+			response.UnprocessableValidation(c, "validation.failed", nil, errorInterface, "[LoginRequest.Validate]")
+			return false
+		}
+	}
+
 	return true
 }
 
 // GetValidatedData returns the validated data
 func (r *LoginRequest) GetValidatedData() map[string]interface{} {
 	return map[string]interface{}{
-		"username": r.Username,
-		"password": r.Password,
+		"username":  r.Username,
+		"password":  r.Password,
+		"abilities": r.Abilities,
 	}
 }
@@ -2,6 +2,7 @@
 package auth
 
 import (
+	"response-std/app/pkg/i18n"
 	"response-std/app/pkg/response"
 	"response-std/libs/external/services"
 
@@ -26,25 +27,25 @@ func (r *RegisterRequest) Validate(c *gin.Context) bool {
 		"password_confirmation": []string{"required"},
 	}
 
-	// Custom messages
-	messages := govalidator.MapData{
+	// Custom messages, berisi translation key dari katalog i18n
+	messages := i18n.Messages(c, govalidator.MapData{
 		"name": []string{
-			"required:Nama wajib diisi",
-			"min:Nama minimal 2 karakter",
-			"max:Nama maksimal 255 karakter",
+			"required:validation.name.required",
+			"min:validation.name.min",
+			"max:validation.name.max",
 		},
 		"email": []string{
-			"required:Email wajib diisi",
-			"email:Format email tidak valid",
+			"required:validation.email.required",
+			"email:validation.email.email",
 		},
 		"password": []string{
-			"required:Password wajib diisi",
-			"min:Password minimal 8 karakter",
+			"required:validation.password.required",
+			"min:validation.password.min",
 		},
 		"password_confirmation": []string{
-			"required:Konfirmasi password wajib diisi",
+			"required:validation.password_confirmation.required",
 		},
-	}
+	})
 
 	// Create validator options
 	opts := govalidator.Options{
@@ -68,25 +69,25 @@ func (r *RegisterRequest) Validate(c *gin.Context) bool {
 		for k, v := range errors {
 			errorInterface[k] = v
 		}
-		services.AppLogger.Debug("Validation failed", errorInterface)
+		services.AppLogger.WithContext(c).Debug("Validation failed", errorInterface)
 
-		response.UnprocessableValidation(c, "Validation failed", nil, errorInterface, "[RegisterRequest]")
+		response.UnprocessableValidation(c, "validation.failed", nil, errorInterface, "[RegisterRequest]")
 		return false
 	}
 
 	// Custom validation: password confirmation
 	if r.Password != r.PasswordConfirmation {
 		errors := map[string][]string{
-			"password_confirmation": {"Password dan konfirmasi password tidak cocok"},
+			"password_confirmation": {i18n.T(c, "validation.password_confirmation.mismatch")},
 		}
 		// Convert errors to map[string]interface{} for logging
 		errorInterface := make(map[string]interface{}, len(errors))
 		for k, v := range errors {
 			errorInterface[k] = v
 		}
-		services.AppLogger.Debug("Validation failed", errorInterface)
+		services.AppLogger.WithContext(c).Debug("Validation failed", errorInterface)
 
-		response.UnprocessableValidation(c, "Validation failed", nil, errorInterface, "[RegisterRequest]")
+		response.UnprocessableValidation(c, "validation.failed", nil, errorInterface, "[RegisterRequest]")
 		return false
 	}
 
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+)
+
+// IdempotencyKey menyimpan respons pertama untuk satu Idempotency-Key (lihat app/pkg/idempotency)
+type IdempotencyKey struct {
+	Key             string     `gorm:"primaryKey;size:64" json:"key"`
+	Fingerprint     string     `gorm:"size:64" json:"fingerprint"`
+	StatusCode      int        `json:"status_code"`
+	ResponseHeaders *string    `gorm:"type:text" json:"-"`
+	ResponseBody    []byte     `gorm:"type:longblob" json:"-"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt       time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
@@ -5,14 +5,18 @@ import (
 )
 
 type PersonalAccessTokens struct {
-	ID            uint       `gorm:"primaryKey" json:"id,omitempty"`
-	TokenableID   uint       `gorm:"index" json:"-"`
-	TokenableType string     `gorm:"size:255" json:"-"`
-	Name          string     `gorm:"size:255" json:"name,omitempty"`
-	Token         string     `gorm:"size:64;unique" json:"-"`
-	Abilities     *string    `gorm:"type:text" json:"abilities,omitempty"`
-	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
-	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at,omitempty"`
-	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+	ID                uint       `gorm:"primaryKey" json:"id,omitempty"`
+	TokenableID       uint       `gorm:"index" json:"-"`
+	TokenableType     string     `gorm:"size:255" json:"-"`
+	Name              string     `gorm:"size:255" json:"name,omitempty"`
+	Token             string     `gorm:"size:64;unique" json:"-"`
+	Abilities         *string    `gorm:"type:text" json:"abilities,omitempty"`
+	CreatedIP         *string    `gorm:"size:45" json:"created_ip,omitempty"`
+	CreatedUserAgent  *string    `gorm:"size:512" json:"created_user_agent,omitempty"`
+	LastUsedIP        *string    `gorm:"size:45" json:"last_used_ip,omitempty"`
+	LastUsedUserAgent *string    `gorm:"size:512" json:"last_used_user_agent,omitempty"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at,omitempty"`
 }
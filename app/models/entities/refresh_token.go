@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+)
+
+// RefreshToken adalah refresh token sekali pakai. Setiap login membuat family baru; setiap rotasi
+// menandai token lama used_at dan membuat token baru di family yang sama (lihat app/pkg/auth/refresh.go).
+type RefreshToken struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"index" json:"user_id"`
+	Family           string     `gorm:"size:36;index" json:"family"`
+	Token            string     `gorm:"size:64;unique" json:"-"`
+	AccessTokenID    *uint      `gorm:"index" json:"access_token_id,omitempty"`
+	Name             string     `gorm:"size:255" json:"name"`
+	Abilities        *string    `gorm:"type:text" json:"abilities,omitempty"`
+	CreatedIP        *string    `gorm:"size:45" json:"created_ip,omitempty"`
+	CreatedUserAgent *string    `gorm:"size:512" json:"created_user_agent,omitempty"`
+	UsedAt           *time.Time `json:"used_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	ExpiresAt        time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"response-std/app/helpers/helper"
+	"response-std/app/pkg/filter"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +16,8 @@ type User struct {
 	EmailVerifiedAt      *time.Time
 	Password             string
 	RememberToken        *string `gorm:"size:100"`
+	Locale               *string `gorm:"size:10"`
+	Version              uint64  `gorm:"not null;default:1"` // naik setiap update, dasar ETag & If-Match
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 	DeletedAt            gorm.DeletedAt         `gorm:"index"`
@@ -29,3 +32,27 @@ func (u *User) CheckPassword(pw string) bool {
 	}
 	return helper.CheckPasswordHash(pw, u.Password)
 }
+
+// PreferredLocale dipakai i18n untuk memilih bahasa respons user yang sedang login
+func (u User) PreferredLocale() string {
+	if u.Locale == nil {
+		return ""
+	}
+	return *u.Locale
+}
+
+// FilterSchema mendeklarasikan field yang boleh dipakai di ?filter[...] dan ?sort=
+func (User) FilterSchema() filter.Schema {
+	dateOps := []filter.Operator{filter.OpEq, filter.OpGt, filter.OpGte, filter.OpLt, filter.OpLte}
+
+	return filter.Schema{
+		Fields: map[string]filter.Field{
+			"id":         {Column: "id", Operators: []filter.Operator{filter.OpEq, filter.OpIn}, Sortable: true},
+			"name":       {Column: "name", Operators: []filter.Operator{filter.OpEq, filter.OpLike}, Sortable: true},
+			"email":      {Column: "email", Operators: []filter.Operator{filter.OpEq, filter.OpLike}, Sortable: true},
+			"created_at": {Column: "created_at", Operators: dateOps, Sortable: true},
+			"updated_at": {Column: "updated_at", Operators: dateOps, Sortable: true},
+		},
+		DefaultSort: "id",
+	}
+}
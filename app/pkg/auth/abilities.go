@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"response-std/app/models/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Wildcard memberi token semua ability (default jika klien tidak meminta scope)
+const Wildcard = "*"
+
+// abilityPattern: "*", "users:read", "users:*", "reports.export"
+var abilityPattern = regexp.MustCompile(`^(\*|[a-z0-9_.\-]+(:([a-z0-9_.\-]+|\*))*)$`)
+
+// Abilities adalah daftar scope milik personal access token (mirip Sanctum)
+type Abilities []string
+
+// Can true jika token memiliki ability tersebut.
+// "*" cocok dengan semua ability, "users:*" cocok dengan "users:read", "users:write", dst.
+func (a Abilities) Can(ability string) bool {
+	for _, granted := range a {
+		if granted == Wildcard || granted == ability {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(granted, ":*"); ok && strings.HasPrefix(ability, prefix+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Cant kebalikan dari Can
+func (a Abilities) Cant(ability string) bool {
+	return !a.Can(ability)
+}
+
+// ValidAbility memeriksa format nama ability
+func ValidAbility(ability string) bool {
+	return abilityPattern.MatchString(ability)
+}
+
+// NormalizeAbilities merapikan ability yang diminta klien (trim, lowercase, tanpa duplikat).
+// Jika kosong, token mendapat "*" seperti default Sanctum.
+func NormalizeAbilities(requested []string) Abilities {
+	seen := make(map[string]bool, len(requested))
+	abilities := make(Abilities, 0, len(requested))
+	for _, ability := range requested {
+		ability = strings.ToLower(strings.TrimSpace(ability))
+		if ability == "" || seen[ability] {
+			continue
+		}
+		if ability == Wildcard {
+			return Abilities{Wildcard}
+		}
+		seen[ability] = true
+		abilities = append(abilities, ability)
+	}
+
+	if len(abilities) == 0 {
+		return Abilities{Wildcard}
+	}
+	return abilities
+}
+
+// EncodeAbilities mengubah abilities menjadi JSON untuk kolom personal_access_tokens.abilities
+func EncodeAbilities(abilities Abilities) *string {
+	body, err := json.Marshal(abilities)
+	if err != nil {
+		body = []byte(`["*"]`)
+	}
+	encoded := string(body)
+	return &encoded
+}
+
+// ParseAbilities membaca kolom abilities. Mendukung JSON (`["users:read"]`) dan format lama
+// `['*']` yang dulu selalu ditulis login. NULL / kosong berarti token tanpa ability.
+func ParseAbilities(raw *string) Abilities {
+	if raw == nil {
+		return Abilities{}
+	}
+	value := strings.TrimSpace(*raw)
+	if value == "" {
+		return Abilities{}
+	}
+
+	var abilities Abilities
+	if err := json.Unmarshal([]byte(value), &abilities); err == nil {
+		return abilities
+	}
+
+	// Format lama: ['*'] atau ['users:read', 'users:write']
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	abilities = Abilities{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.Trim(strings.TrimSpace(part), `'"`)
+		if part != "" {
+			abilities = append(abilities, part)
+		}
+	}
+	return abilities
+}
+
+// TokenAbilities mengambil abilities token aktif (diset oleh AuthMiddleware)
+func TokenAbilities(c *gin.Context) Abilities {
+	if value, exists := c.Get("abilities"); exists {
+		if abilities, ok := value.(Abilities); ok {
+			return abilities
+		}
+	}
+	if value, exists := c.Get("token"); exists {
+		if token, ok := value.(entities.PersonalAccessTokens); ok {
+			return ParseAbilities(token.Abilities)
+		}
+	}
+	return Abilities{}
+}
+
+// TokenCan memeriksa ability token aktif, untuk dipakai di dalam handler
+// contoh penggunaan:
+//
+//	if !auth.TokenCan(c, "users:delete") {
+//	    response.Forbidden(c, "Token ability denied", response.WithCode(response.CodeAuthAbilityRequired, nil), "[DeleteUser]")
+//	    return
+//	}
+func TokenCan(c *gin.Context, ability string) bool {
+	return TokenAbilities(c).Can(ability)
+}
+
+// TokenCant kebalikan dari TokenCan
+func TokenCant(c *gin.Context, ability string) bool {
+	return !TokenCan(c, ability)
+}
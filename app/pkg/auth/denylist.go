@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Denylist menyimpan JWT yang sudah dicabut sampai waktu kadaluarsanya lewat.
+// Key berupa jti token, atau "sid:<family>" untuk mencabut semua JWT dari satu sesi.
+type Denylist interface {
+	Add(ctx context.Context, key string, until time.Time) error
+	Contains(ctx context.Context, key string) (bool, error)
+}
+
+// ---------------------------
+// MEMORY DENYLIST
+// ---------------------------
+// MemoryDenylist cocok untuk satu instance; isinya hilang saat restart
+type MemoryDenylist struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	nextSweep time.Time
+}
+
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{entries: make(map[string]time.Time)}
+}
+
+func (d *MemoryDenylist) Add(_ context.Context, key string, until time.Time) error {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Bersihkan entry yang sudah lewat paling sering sekali per menit
+	if now.After(d.nextSweep) {
+		for k, exp := range d.entries {
+			if now.After(exp) {
+				delete(d.entries, k)
+			}
+		}
+		d.nextSweep = now.Add(time.Minute)
+	}
+
+	if existing, ok := d.entries[key]; !ok || until.After(existing) {
+		d.entries[key] = until
+	}
+	return nil
+}
+
+func (d *MemoryDenylist) Contains(_ context.Context, key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	until, ok := d.entries[key]
+	return ok && time.Now().Before(until), nil
+}
+
+// ---------------------------
+// REDIS DENYLIST
+// ---------------------------
+// RedisDenylist berbagi denylist antar instance, entry otomatis hilang lewat TTL Redis
+type RedisDenylist struct {
+	client redis.Cmdable
+	prefix string
+}
+
+func NewRedisDenylist(client redis.Cmdable, prefix string) *RedisDenylist {
+	return &RedisDenylist{client: client, prefix: prefix}
+}
+
+func (d *RedisDenylist) Add(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, d.prefix+key, 1, ttl).Err()
+}
+
+func (d *RedisDenylist) Contains(ctx context.Context, key string) (bool, error) {
+	err := d.client.Get(ctx, d.prefix+key).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,379 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"response-std/app/models/entities"
+	"response-std/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Mode AUTH_GUARD
+const (
+	GuardToken = "token" // opaque personal access token (personal_access_tokens)
+	GuardJWT   = "jwt"   // JWT stateless saja
+	GuardBoth  = "both"  // sesi login memakai JWT, personal access token tetap diterima
+)
+
+// insecureSecrets ditolak di production
+var insecureSecrets = map[string]bool{"": true, "supersecretkey": true}
+
+var (
+	ErrJWTInvalid = errors.New("jwt is invalid")
+	ErrJWTExpired = errors.New("jwt has expired")
+	ErrJWTRevoked = errors.New("jwt has been revoked")
+)
+
+// Claims adalah isi JWT access token
+type Claims struct {
+	Name  string   `json:"name,omitempty"`
+	Email string   `json:"email,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+	// Permissions adalah permission efektif (langsung + dari role) saat token diterbitkan
+	Permissions []string  `json:"perms,omitempty"`
+	Abilities   Abilities `json:"abilities,omitempty"`
+	// SessionID adalah family refresh token; dipakai untuk mencabut semua JWT dari sesi yang sama
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID mengambil ID user dari claim sub
+func (c Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id), err
+}
+
+// JWTGuard menerbitkan dan memverifikasi JWT access token
+type JWTGuard struct {
+	method     jwt.SigningMethod
+	keyID      string
+	signKey    interface{}
+	verifyKeys map[string]interface{}
+	issuer     string
+	audience   string
+	sessionTTL time.Duration
+	denylist   Denylist
+}
+
+var (
+	defaultJWT *JWTGuard
+	jwtMutex   sync.RWMutex
+)
+
+// GuardMode mengembalikan AUTH_GUARD (token | jwt | both), default token
+func GuardMode() string {
+	if config.ENV == nil {
+		return GuardToken
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(config.ENV.AuthGuard)); mode {
+	case GuardJWT, GuardBoth:
+		return mode
+	default:
+		return GuardToken
+	}
+}
+
+// InitJWT membuat JWT guard dari config jika AUTH_GUARD memakai JWT (jwt | both)
+func InitJWT(cfg *config.Config) error {
+	if GuardMode() == GuardToken {
+		SetJWT(nil)
+		return nil
+	}
+
+	var denylist Denylist = NewMemoryDenylist()
+	if strings.ToLower(cfg.JWTDenylistStore) == "redis" {
+		denylist = NewRedisDenylist(config.RedisClient(), "jwt:denylist:")
+	}
+
+	guard, err := NewJWTGuard(cfg, denylist)
+	if err != nil {
+		return err
+	}
+	SetJWT(guard)
+	return nil
+}
+
+// SetJWT mengganti guard default (nil = JWT tidak aktif)
+func SetJWT(g *JWTGuard) {
+	jwtMutex.Lock()
+	defer jwtMutex.Unlock()
+	defaultJWT = g
+}
+
+// DefaultJWT mengembalikan guard yang dipakai AuthMiddleware, nil jika AUTH_GUARD=token
+func DefaultJWT() *JWTGuard {
+	jwtMutex.RLock()
+	defer jwtMutex.RUnlock()
+	return defaultJWT
+}
+
+// NewJWTGuard membaca algoritma & key dari config.
+// HS256 memakai JWT_SECRET; RS256/EdDSA memakai JWT_PRIVATE_KEY (PEM). JWT_VERIFY_KEYS berisi key lama per kid.
+func NewJWTGuard(cfg *config.Config, denylist Denylist) (*JWTGuard, error) {
+	keyID := strings.TrimSpace(cfg.JWTKeyID)
+	if keyID == "" {
+		keyID = "v1"
+	}
+
+	g := &JWTGuard{
+		keyID:      keyID,
+		verifyKeys: make(map[string]interface{}),
+		issuer:     cfg.JWTIssuer,
+		audience:   cfg.JWTAudience,
+		sessionTTL: DefaultSessionTTL().Access,
+		denylist:   denylist,
+	}
+	if g.issuer == "" {
+		g.issuer = cfg.APP_NAME
+	}
+
+	switch strings.ToUpper(cfg.JWTAlgorithm) {
+	case "", "HS256":
+		if cfg.Environment == "production" && (insecureSecrets[cfg.JWT_SECRET] || len(cfg.JWT_SECRET) < 32) {
+			return nil, fmt.Errorf("JWT_SECRET must be set to at least 32 random characters in production")
+		}
+		if cfg.JWT_SECRET == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required for HS256")
+		}
+		g.method = jwt.SigningMethodHS256
+		g.signKey = []byte(cfg.JWT_SECRET)
+		g.verifyKeys[keyID] = []byte(cfg.JWT_SECRET)
+	case "RS256", "EDDSA":
+		if strings.ToUpper(cfg.JWTAlgorithm) == "RS256" {
+			g.method = jwt.SigningMethodRS256
+		} else {
+			g.method = jwt.SigningMethodEdDSA
+		}
+		signKey, err := g.loadPrivateKey(cfg.JWTPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		g.signKey = signKey
+		g.verifyKeys[keyID] = signKey.(crypto.Signer).Public()
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q (use HS256, RS256 or EdDSA)", cfg.JWTAlgorithm)
+	}
+
+	for _, entry := range cfg.JWTVerifyKeys {
+		kid, value, ok := strings.Cut(entry, "=")
+		kid, value = strings.TrimSpace(kid), strings.TrimSpace(value)
+		if !ok || kid == "" || value == "" {
+			return nil, fmt.Errorf("invalid JWT_VERIFY_KEYS entry %q (use kid=secret or kid=path/to/public.pem)", entry)
+		}
+		if kid == keyID {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS entry %q reuses the current JWT_KEY_ID", kid)
+		}
+
+		key, err := g.loadVerifyKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS %s: %w", kid, err)
+		}
+		g.verifyKeys[kid] = key
+	}
+
+	return g, nil
+}
+
+func (g *JWTGuard) loadPrivateKey(path string) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY is required for %s", g.method.Alg())
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT_PRIVATE_KEY: %w", err)
+	}
+
+	if g.method == jwt.SigningMethodRS256 {
+		return jwt.ParseRSAPrivateKeyFromPEM(pem)
+	}
+	return jwt.ParseEdPrivateKeyFromPEM(pem)
+}
+
+// loadVerifyKey: secret apa adanya untuk HS256, path PEM public key untuk RS256/EdDSA
+func (g *JWTGuard) loadVerifyKey(value string) (interface{}, error) {
+	if g.method == jwt.SigningMethodHS256 {
+		return []byte(value), nil
+	}
+
+	pem, err := os.ReadFile(value)
+	if err != nil {
+		return nil, err
+	}
+	if g.method == jwt.SigningMethodRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(pem)
+	}
+	return jwt.ParseEdPublicKeyFromPEM(pem)
+}
+
+// Issue menerbitkan JWT access token untuk user dengan kid key saat ini. Roles dari user.Roles,
+// permission dari user.Permissions + user.Roles[].Permissions (preload "Roles.Permissions" & "Permissions").
+func (g *JWTGuard) Issue(user entities.User, abilities Abilities, sessionID string, expiresAt time.Time) (string, Claims, error) {
+	roles := make([]string, 0, len(user.Roles))
+	seen := make(map[string]bool)
+	var permissions []string
+	addPermission := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			permissions = append(permissions, name)
+		}
+	}
+	for _, permission := range user.Permissions {
+		addPermission(permission.Name)
+	}
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+		for _, permission := range role.Permissions {
+			addPermission(permission.Name)
+		}
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Name:        user.Name,
+		Email:       user.Email,
+		Roles:       roles,
+		Permissions: permissions,
+		Abilities:   abilities,
+		SessionID:   sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Issuer:    g.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	if g.audience != "" {
+		claims.Audience = jwt.ClaimStrings{g.audience}
+	}
+
+	token := jwt.NewWithClaims(g.method, claims)
+	token.Header["kid"] = g.keyID
+
+	signed, err := token.SignedString(g.signKey)
+	return signed, claims, err
+}
+
+// Parse memverifikasi signature (key dipilih dari kid), exp/nbf, issuer, audience, lalu denylist.
+// JWT tanpa kid hanya diterima selama belum ada key lama (JWT_VERIFY_KEYS); setelah rotasi kid wajib.
+func (g *JWTGuard) Parse(ctx context.Context, raw string) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{g.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if g.issuer != "" {
+		options = append(options, jwt.WithIssuer(g.issuer))
+	}
+	if g.audience != "" {
+		options = append(options, jwt.WithAudience(g.audience))
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			if len(g.verifyKeys) > 1 {
+				return nil, errors.New("missing kid")
+			}
+			kid = g.keyID
+		}
+		key, ok := g.verifyKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		return key, nil
+	}, options...)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, fmt.Errorf("%w: %v", ErrJWTExpired, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+	}
+	if _, err := claims.UserID(); err != nil || claims.ID == "" {
+		return nil, fmt.Errorf("%w: missing sub or jti", ErrJWTInvalid)
+	}
+
+	for _, key := range []string{claims.ID, sessionKey(claims.SessionID)} {
+		if key == "" {
+			continue
+		}
+		revoked, err := g.denylist.Contains(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrJWTRevoked
+		}
+	}
+
+	return &claims, nil
+}
+
+// Revoke memasukkan jti token ke denylist sampai token kadaluarsa (logout)
+func (g *JWTGuard) Revoke(ctx context.Context, claims Claims) error {
+	until := time.Now().Add(g.sessionTTL)
+	if claims.ExpiresAt != nil {
+		until = claims.ExpiresAt.Time
+	}
+	return g.denylist.Add(ctx, claims.ID, until)
+}
+
+// RevokeSession mencabut semua JWT dengan sid tersebut. Cukup disimpan selama umur access token,
+// karena JWT sesi itu yang lebih baru tidak bisa diterbitkan lagi setelah family dicabut.
+func (g *JWTGuard) RevokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return g.denylist.Add(ctx, sessionKey(sessionID), time.Now().Add(g.sessionTTL))
+}
+
+// LooksLikeJWT membedakan JWT (header.payload.signature) dari opaque token "id|plain"
+func LooksLikeJWT(raw string) bool {
+	return strings.Count(raw, ".") == 2 && !strings.Contains(raw, "|")
+}
+
+// UserFromClaims membentuk user stateless dari claims (ID, nama, email, nama role & permission efektif,
+// tanpa query database). Permission disimpan sebagai permission langsung, sehingga PermissionMiddleware
+// tetap jalan; perubahan permission baru berlaku setelah access token diperbarui.
+func UserFromClaims(claims *Claims) entities.User {
+	id, _ := claims.UserID()
+	user := entities.User{ID: id, Name: claims.Name, Email: claims.Email}
+	for _, role := range claims.Roles {
+		user.Roles = append(user.Roles, entities.Roles{Name: role})
+	}
+	for _, permission := range claims.Permissions {
+		user.Permissions = append(user.Permissions, entities.Permission{Name: permission})
+	}
+	return user
+}
+
+// CurrentClaims mengambil claims JWT request ini (diset oleh AuthMiddleware), false untuk opaque token
+func CurrentClaims(c *gin.Context) (Claims, bool) {
+	value, exists := c.Get("jwt_claims")
+	if !exists {
+		return Claims{}, false
+	}
+
+	claims, ok := value.(Claims)
+	return claims, ok
+}
+
+func sessionKey(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	return "sid:" + sessionID
+}
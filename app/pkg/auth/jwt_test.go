@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"response-std/app/models/entities"
+	"response-std/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestJWT(t *testing.T, keyID, secret string, verifyKeys ...string) *JWTGuard {
+	t.Helper()
+	g, err := NewJWTGuard(&config.Config{
+		APP_NAME:      "test",
+		JWT_SECRET:    secret,
+		JWTKeyID:      keyID,
+		JWTVerifyKeys: verifyKeys,
+	}, NewMemoryDenylist())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func testUser() entities.User {
+	return entities.User{
+		ID:          7,
+		Name:        "alice",
+		Email:       "alice@example.com",
+		Permissions: []entities.Permission{{Name: "users.export"}},
+		Roles: []entities.Roles{{
+			Name:        "admin",
+			Permissions: []entities.Permission{{Name: "users.delete"}, {Name: "users.export"}},
+		}},
+	}
+}
+
+func TestJWTCarriesPermissions(t *testing.T) {
+	g := newTestJWT(t, "v1", "secret-v1")
+
+	raw, _, err := g.Issue(testUser(), Abilities{"*"}, "family-1", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := g.Parse(context.Background(), raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := UserFromClaims(claims)
+	if user.ID != 7 || len(user.Roles) != 1 || user.Roles[0].Name != "admin" {
+		t.Errorf("user = %+v, want id 7 with role admin", user)
+	}
+
+	got := make(map[string]int)
+	for _, p := range user.Permissions {
+		got[p.Name]++
+	}
+	if len(got) != 2 || got["users.delete"] != 1 || got["users.export"] != 1 {
+		t.Errorf("permissions = %v, want users.delete & users.export once each", got)
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Minute)
+
+	old := newTestJWT(t, "v1", "secret-v1")
+	oldToken, _, err := old.Issue(testUser(), nil, "", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestJWT(t, "v2", "secret-v2", "v1=secret-v1")
+	if _, err := rotated.Parse(ctx, oldToken); err != nil {
+		t.Errorf("token signed with old kid should verify after rotation: %v", err)
+	}
+
+	newToken, _, _ := rotated.Issue(testUser(), nil, "", expires)
+	if _, err := old.Parse(ctx, newToken); !errors.Is(err, ErrJWTInvalid) {
+		t.Errorf("unknown kid err = %v, want ErrJWTInvalid", err)
+	}
+
+	// Token tanpa kid: diterima tanpa key lama, ditolak setelah rotasi
+	noKid := func(secret string) string {
+		claims := Claims{RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti-1",
+			Subject:   "7",
+			Issuer:    "test",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		}}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	if _, err := old.Parse(ctx, noKid("secret-v1")); err != nil {
+		t.Errorf("missing kid without verify keys should fall back to current key: %v", err)
+	}
+	if _, err := rotated.Parse(ctx, noKid("secret-v2")); !errors.Is(err, ErrJWTInvalid) {
+		t.Errorf("missing kid with verify keys err = %v, want ErrJWTInvalid", err)
+	}
+}
+
+func TestJWTRejectsExpiredAndTampered(t *testing.T) {
+	ctx := context.Background()
+	g := newTestJWT(t, "v1", "secret-v1")
+
+	expired, _, _ := g.Issue(testUser(), nil, "", time.Now().Add(-time.Minute))
+	if _, err := g.Parse(ctx, expired); !errors.Is(err, ErrJWTExpired) {
+		t.Errorf("expired err = %v, want ErrJWTExpired", err)
+	}
+
+	other := newTestJWT(t, "v1", "another-secret")
+	forged, _, _ := other.Issue(testUser(), nil, "", time.Now().Add(time.Minute))
+	if _, err := g.Parse(ctx, forged); !errors.Is(err, ErrJWTInvalid) {
+		t.Errorf("wrong signature err = %v, want ErrJWTInvalid", err)
+	}
+}
+
+func TestJWTDenylist(t *testing.T) {
+	ctx := context.Background()
+	g := newTestJWT(t, "v1", "secret-v1")
+	expires := time.Now().Add(time.Minute)
+
+	first, firstClaims, _ := g.Issue(testUser(), nil, "family-1", expires)
+	second, _, _ := g.Issue(testUser(), nil, "family-1", expires)
+	otherSession, _, _ := g.Issue(testUser(), nil, "family-2", expires)
+
+	if err := g.Revoke(ctx, firstClaims); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Parse(ctx, first); !errors.Is(err, ErrJWTRevoked) {
+		t.Errorf("revoked jti err = %v, want ErrJWTRevoked", err)
+	}
+	if _, err := g.Parse(ctx, second); err != nil {
+		t.Errorf("other token of the session should stay valid after jti revoke: %v", err)
+	}
+
+	if err := g.RevokeSession(ctx, "family-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Parse(ctx, second); !errors.Is(err, ErrJWTRevoked) {
+		t.Errorf("revoked session err = %v, want ErrJWTRevoked", err)
+	}
+	if _, err := g.Parse(ctx, otherSession); err != nil {
+		t.Errorf("other session should stay valid: %v", err)
+	}
+}
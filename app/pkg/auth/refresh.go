@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"response-std/app/models/entities"
+	"response-std/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or revoked")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	// ErrRefreshTokenReused berarti refresh token yang sudah dipakai dikirim ulang; seluruh family sudah dicabut
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// SessionTTL adalah umur access token dan refresh token
+type SessionTTL struct {
+	Access  time.Duration
+	Refresh time.Duration
+}
+
+// DefaultSessionTTL membaca ACCESS_TOKEN_TTL dan REFRESH_TOKEN_TTL
+func DefaultSessionTTL() SessionTTL {
+	ttl := SessionTTL{Access: DefaultAccessTokenTTL, Refresh: DefaultRefreshTokenTTL}
+	if config.ENV != nil {
+		if config.ENV.AccessTokenTTL > 0 {
+			ttl.Access = config.ENV.AccessTokenTTL
+		}
+		if config.ENV.RefreshTokenTTL > 0 {
+			ttl.Refresh = config.ENV.RefreshTokenTTL
+		}
+	}
+	return ttl
+}
+
+// Session adalah pasangan access token + refresh token hasil login atau rotasi.
+// Token plain hanya ada di sini; database hanya menyimpan hash.
+type Session struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	Abilities        Abilities
+	Family           string
+}
+
+// IssueSession membuat access token + refresh token di family baru (dipanggil saat login)
+func IssueSession(db *gorm.DB, userID uint, name string, abilities Abilities, client Client, ttl SessionTTL) (Session, error) {
+	return issueSession(db, userID, uuid.New().String(), name, abilities, client, ttl)
+}
+
+func issueSession(db *gorm.DB, userID uint, family, name string, abilities Abilities, client Client, ttl SessionTTL) (Session, error) {
+	var session Session
+	err := db.Transaction(func(tx *gorm.DB) error {
+		accessExpiresAt := time.Now().Add(ttl.Access)
+		accessTokenID, accessPlain, err := issueAccessToken(tx, userID, family, name, abilities, accessExpiresAt, client)
+		if err != nil {
+			return err
+		}
+
+		plain, err := NewPlainToken()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		refresh := entities.RefreshToken{
+			UserID:           userID,
+			Family:           family,
+			Token:            HashToken(plain),
+			AccessTokenID:    accessTokenID,
+			Name:             name,
+			Abilities:        EncodeAbilities(abilities),
+			CreatedIP:        nonEmpty(client.IP),
+			CreatedUserAgent: nonEmpty(client.UserAgent),
+			ExpiresAt:        now.Add(ttl.Refresh),
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		if err := tx.Create(&refresh).Error; err != nil {
+			return err
+		}
+
+		session = Session{
+			AccessToken:      accessPlain,
+			AccessExpiresAt:  accessExpiresAt,
+			RefreshToken:     fmt.Sprintf("%d|%s", refresh.ID, plain),
+			RefreshExpiresAt: refresh.ExpiresAt,
+			Abilities:        abilities,
+			Family:           family,
+		}
+		return nil
+	})
+	return session, err
+}
+
+// issueAccessToken menerbitkan JWT (sid = family) jika JWT guard aktif, selain itu opaque personal access token.
+// ID yang dikembalikan nil untuk JWT karena tidak ada baris di personal_access_tokens.
+func issueAccessToken(tx *gorm.DB, userID uint, family, name string, abilities Abilities, expiresAt time.Time, client Client) (*uint, string, error) {
+	if guard := DefaultJWT(); guard != nil && GuardMode() != GuardToken {
+		var user entities.User
+		if err := tx.Preload("Roles.Permissions").Preload("Permissions").First(&user, userID).Error; err != nil {
+			return nil, "", err
+		}
+		signed, _, err := guard.Issue(user, abilities, family, expiresAt)
+		return nil, signed, err
+	}
+
+	token, plain, err := IssueToken(tx, userID, name, abilities, &expiresAt, client)
+	if err != nil {
+		return nil, "", err
+	}
+	return &token.ID, plain, nil
+}
+
+// RotateRefreshToken menukar refresh token "id|plain" dengan sesi baru di family yang sama.
+// Refresh token hanya bisa dipakai sekali: jika token yang sudah dipakai dikirim lagi, seluruh
+// family (refresh token + access token-nya) dicabut dan ErrRefreshTokenReused dikembalikan.
+func RotateRefreshToken(db *gorm.DB, raw string, client Client, ttl SessionTTL) (Session, error) {
+	parts := strings.SplitN(raw, "|", 2)
+	if len(parts) != 2 {
+		return Session{}, ErrRefreshTokenInvalid
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return Session{}, ErrRefreshTokenInvalid
+	}
+
+	var current entities.RefreshToken
+	if err := db.Where("id = ? AND token = ?", id, HashToken(parts[1])).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Session{}, ErrRefreshTokenInvalid
+		}
+		return Session{}, err
+	}
+
+	if current.RevokedAt != nil {
+		return Session{}, ErrRefreshTokenInvalid
+	}
+	if current.UsedAt != nil {
+		return Session{}, revokeReusedFamily(db, current.Family)
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return Session{}, ErrRefreshTokenExpired
+	}
+
+	var session Session
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Tandai used secara atomik: dari beberapa request paralel dengan token yang sama hanya satu yang menang
+		result := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			UpdateColumns(map[string]interface{}{"used_at": now, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		// Access token lama ikut dicabut
+		if current.AccessTokenID != nil {
+			if err := tx.Where("id = ?", *current.AccessTokenID).Delete(&entities.PersonalAccessTokens{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		session, err = issueSession(tx, current.UserID, current.Family, current.Name, ParseAbilities(current.Abilities), client, ttl)
+		return err
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		return Session{}, revokeReusedFamily(db, current.Family)
+	}
+	return session, err
+}
+
+// RevokeFamily mencabut semua refresh token di family dan menghapus access token yang diterbitkan darinya.
+// JWT dari family ini dimasukkan ke denylist lewat sid.
+func RevokeFamily(db *gorm.DB, family string) error {
+	if guard := DefaultJWT(); guard != nil {
+		if err := guard.RevokeSession(db.Statement.Context, family); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var accessIDs []uint
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("family = ? AND access_token_id IS NOT NULL", family).
+			Pluck("access_token_id", &accessIDs).Error; err != nil {
+			return err
+		}
+		if len(accessIDs) > 0 {
+			if err := tx.Where("id IN ?", accessIDs).Delete(&entities.PersonalAccessTokens{}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&entities.RefreshToken{}).
+			Where("family = ? AND revoked_at IS NULL", family).
+			UpdateColumns(map[string]interface{}{"revoked_at": now, "updated_at": now}).Error
+	})
+}
+
+// RevokeFamiliesOf mencabut family refresh token milik access token tersebut (logout / revoke token)
+func RevokeFamiliesOf(db *gorm.DB, accessTokenIDs ...uint) error {
+	if len(accessTokenIDs) == 0 {
+		return nil
+	}
+
+	var families []string
+	if err := db.Model(&entities.RefreshToken{}).
+		Where("access_token_id IN ?", accessTokenIDs).
+		Distinct().Pluck("family", &families).Error; err != nil {
+		return err
+	}
+	for _, family := range families {
+		if err := RevokeFamily(db, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneRefreshTokens menghapus refresh token yang sudah kadaluarsa. Token used/revoked yang belum
+// kadaluarsa sengaja disimpan agar replay tetap terdeteksi sebagai reuse.
+func PruneRefreshTokens(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at < ?", time.Now()).Delete(&entities.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
+
+func revokeReusedFamily(db *gorm.DB, family string) error {
+	if err := RevokeFamily(db, family); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
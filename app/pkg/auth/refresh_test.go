@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"response-std/app/models/entities"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newRefreshTestDB(t *testing.T) (*gorm.DB, uint) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entities.User{}, &entities.PersonalAccessTokens{}, &entities.RefreshToken{}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := entities.User{Name: "alice", Email: "alice@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, user.ID
+}
+
+var testTTL = SessionTTL{Access: time.Minute, Refresh: time.Hour}
+
+func countAccessTokens(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	db.Model(&entities.PersonalAccessTokens{}).Count(&count)
+	return count
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	db, userID := newRefreshTestDB(t)
+
+	first, err := IssueSession(db, userID, "login", Abilities{"*"}, Client{}, testTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := RotateRefreshToken(db, first.RefreshToken, Client{}, testTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("rotation must issue a new access & refresh token")
+	}
+	if second.Family != first.Family {
+		t.Errorf("family = %q, want %q", second.Family, first.Family)
+	}
+	if n := countAccessTokens(t, db); n != 1 {
+		t.Errorf("access tokens = %d, want 1 (old one revoked)", n)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	db, userID := newRefreshTestDB(t)
+
+	first, _ := IssueSession(db, userID, "login", nil, Client{}, testTTL)
+	second, err := RotateRefreshToken(db, first.RefreshToken, Client{}, testTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := IssueSession(db, userID, "other device", nil, Client{}, testTTL)
+
+	// Replay token yang sudah dipakai: dianggap dicuri, seluruh family dicabut
+	if _, err := RotateRefreshToken(db, first.RefreshToken, Client{}, testTTL); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replay err = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := RotateRefreshToken(db, second.RefreshToken, Client{}, testTTL); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("latest token of reused family err = %v, want ErrRefreshTokenInvalid", err)
+	}
+	if n := countAccessTokens(t, db); n != 1 {
+		t.Errorf("access tokens = %d, want 1 (only the other device)", n)
+	}
+
+	if _, err := RotateRefreshToken(db, other.RefreshToken, Client{}, testTTL); err != nil {
+		t.Errorf("other family should be unaffected: %v", err)
+	}
+}
+
+func TestRotateRefreshTokenRejects(t *testing.T) {
+	db, userID := newRefreshTestDB(t)
+
+	session, _ := IssueSession(db, userID, "login", nil, Client{}, testTTL)
+	expired, _ := IssueSession(db, userID, "login", nil, Client{}, SessionTTL{Access: time.Minute, Refresh: -time.Minute})
+
+	cases := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"malformed", "not-a-token", ErrRefreshTokenInvalid},
+		{"non numeric id", "abc|secret", ErrRefreshTokenInvalid},
+		{"wrong secret", session.RefreshToken + "x", ErrRefreshTokenInvalid},
+		{"expired", expired.RefreshToken, ErrRefreshTokenExpired},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := RotateRefreshToken(db, tc.raw, Client{}, testTTL); !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
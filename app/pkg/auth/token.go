@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"response-std/app/models/entities"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TokenableUser adalah tokenable_type untuk token milik user
+const TokenableUser = "App\\entities\\User"
+
+const tokenCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// maxUserAgentLength mengikuti panjang kolom *_user_agent
+const maxUserAgentLength = 512
+
+// Client adalah metadata klien yang dicatat saat token dibuat dan dipakai
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// ClientFromRequest mengambil IP & user agent dari request
+func ClientFromRequest(c *gin.Context) Client {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return Client{IP: c.ClientIP(), UserAgent: userAgent}
+}
+
+// NewPlainToken membuat token acak 48 karakter seperti default Sanctum
+func NewPlainToken() (string, error) {
+	b := make([]byte, 48)
+	max := big.NewInt(int64(len(tokenCharset)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = tokenCharset[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// HashToken meng-hash token plain (SHA-256 hex) seperti yang disimpan di kolom token
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueToken membuat personal access token baru untuk user dan mengembalikan token
+// dalam format "id|plain" yang hanya bisa ditampilkan sekali. expiresAt nil = tidak kadaluarsa.
+// contoh penggunaan:
+// token, plain, err := auth.IssueToken(db, user.ID, "ci-deploy", auth.NormalizeAbilities(req.Abilities), nil, auth.ClientFromRequest(c))
+func IssueToken(db *gorm.DB, userID uint, name string, abilities Abilities, expiresAt *time.Time, client Client) (entities.PersonalAccessTokens, string, error) {
+	plain, err := NewPlainToken()
+	if err != nil {
+		return entities.PersonalAccessTokens{}, "", err
+	}
+
+	now := time.Now()
+	token := entities.PersonalAccessTokens{
+		TokenableID:      userID,
+		TokenableType:    TokenableUser,
+		Name:             name,
+		Token:            HashToken(plain),
+		Abilities:        EncodeAbilities(abilities),
+		CreatedIP:        nonEmpty(client.IP),
+		CreatedUserAgent: nonEmpty(client.UserAgent),
+		ExpiresAt:        expiresAt,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := db.Create(&token).Error; err != nil {
+		return entities.PersonalAccessTokens{}, "", err
+	}
+	if token.ID == 0 {
+		return entities.PersonalAccessTokens{}, "", fmt.Errorf("failed to get token id")
+	}
+
+	return token, fmt.Sprintf("%d|%s", token.ID, plain), nil
+}
+
+// TouchToken mencatat pemakaian token (last_used_at, IP, user agent). Debounce: hanya menulis
+// jika last_used_at lebih lama dari interval, agar tidak ada UPDATE di setiap request.
+func TouchToken(db *gorm.DB, token *entities.PersonalAccessTokens, client Client, interval time.Duration) error {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < interval {
+		return nil
+	}
+
+	// UpdateColumns: updated_at tidak ikut berubah, updated_at menandai perubahan token (nama/abilities)
+	err := db.Model(&entities.PersonalAccessTokens{}).Where("id = ?", token.ID).UpdateColumns(map[string]interface{}{
+		"last_used_at":         now,
+		"last_used_ip":         nonEmpty(client.IP),
+		"last_used_user_agent": nonEmpty(client.UserAgent),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	token.LastUsedAt = &now
+	token.LastUsedIP = nonEmpty(client.IP)
+	token.LastUsedUserAgent = nonEmpty(client.UserAgent)
+	return nil
+}
+
+// PruneTokens menghapus token yang sudah kadaluarsa dan (jika unusedFor > 0) token yang tidak
+// dipakai selama unusedFor. Token yang belum pernah dipakai dihitung dari created_at.
+func PruneTokens(db *gorm.DB, unusedFor time.Duration) (int64, error) {
+	now := time.Now()
+	query := db.Where("expires_at IS NOT NULL AND expires_at < ?", now)
+	if unusedFor > 0 {
+		cutoff := now.Add(-unusedFor)
+		query = query.Or("last_used_at < ?", cutoff).
+			Or("last_used_at IS NULL AND created_at < ?", cutoff)
+	}
+
+	result := query.Delete(&entities.PersonalAccessTokens{})
+	return result.RowsAffected, result.Error
+}
+
+// ActiveTokens mengambil token user yang belum kadaluarsa, terbaru dulu
+func ActiveTokens(db *gorm.DB, userID uint) ([]entities.PersonalAccessTokens, error) {
+	var tokens []entities.PersonalAccessTokens
+	err := db.Where("tokenable_id = ? AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeToken menghapus satu token milik user beserta family refresh token-nya.
+// gorm.ErrRecordNotFound jika token bukan milik user.
+func RevokeToken(db *gorm.DB, userID, tokenID uint) error {
+	result := db.Where("id = ? AND tokenable_id = ?", tokenID, userID).Delete(&entities.PersonalAccessTokens{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return RevokeFamiliesOf(db, tokenID)
+}
+
+// RevokeOtherTokens menghapus semua token user kecuali exceptID (0 = hapus semua),
+// termasuk family refresh token-nya
+func RevokeOtherTokens(db *gorm.DB, userID, exceptID uint) (int64, error) {
+	query := db.Model(&entities.PersonalAccessTokens{}).Where("tokenable_id = ?", userID)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := db.Where("id IN ?", ids).Delete(&entities.PersonalAccessTokens{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, RevokeFamiliesOf(db, ids...)
+}
+
+// CurrentToken mengambil token yang dipakai request ini (diset oleh AuthMiddleware)
+func CurrentToken(c *gin.Context) (entities.PersonalAccessTokens, bool) {
+	value, exists := c.Get("token")
+	if !exists {
+		return entities.PersonalAccessTokens{}, false
+	}
+
+	token, ok := value.(entities.PersonalAccessTokens)
+	return token, ok
+}
+
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,270 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operator adalah operator perbandingan yang boleh dipakai di ?filter[field][op]=value
+type Operator string
+
+const (
+	OpEq   Operator = "eq"
+	OpNeq  Operator = "neq"
+	OpGt   Operator = "gt"
+	OpGte  Operator = "gte"
+	OpLt   Operator = "lt"
+	OpLte  Operator = "lte"
+	OpLike Operator = "like"
+	OpIn   Operator = "in"
+)
+
+// Field mendeklarasikan satu field yang boleh difilter/di-sort.
+// Column adalah nama kolom asli di database; nama field di query string tidak pernah
+// dipakai langsung sebagai kolom.
+type Field struct {
+	Column    string
+	Operators []Operator // kosong berarti field tidak bisa difilter
+	Sortable  bool
+}
+
+// Schema adalah whitelist field untuk satu entity
+type Schema struct {
+	Fields      map[string]Field
+	DefaultSort string // format sama dengan ?sort=, mis. "-created_at"
+	// TieBreaker adalah kolom unik yang selalu ditambahkan di akhir ORDER BY agar urutan
+	// stabil antar halaman offset (default "id")
+	TieBreaker string
+}
+
+// likeEscape adalah karakter escape untuk LIKE; "!" dipakai karena berlaku sama di MySQL,
+// PostgreSQL dan SQLite (backslash butuh escape berbeda per database)
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// Filterable diimplementasikan entity yang punya schema filter/sort
+type Filterable interface {
+	FilterSchema() Schema
+}
+
+// Condition adalah satu filter yang sudah divalidasi
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+// Sort adalah satu urutan yang sudah divalidasi
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Query adalah hasil parsing ?filter[...] dan ?sort=
+type Query struct {
+	Conditions []Condition
+	Sorts      []Sort
+}
+
+// For membaca filter & sort untuk entity yang mengimplementasikan Filterable
+// contoh penggunaan:
+//
+//	q, errs := filter.For(c, entities.User{})
+//	if errs != nil {
+//	    return response.ValidationError("Invalid filter or sort", errs)
+//	}
+//	db.Scopes(q.Scope())
+func For(c *gin.Context, entity Filterable) (Query, map[string]interface{}) {
+	return Parse(c.Request.URL.Query(), entity.FilterSchema())
+}
+
+// Parse memvalidasi query string terhadap schema. Error dikembalikan dalam bentuk
+// {param: [pesan]} yang sama dengan response.UnprocessableValidation; nil jika valid.
+func Parse(values url.Values, schema Schema) (Query, map[string]interface{}) {
+	var q Query
+	errs := make(map[string]interface{})
+
+	addErr := func(key, msg string) {
+		existing, _ := errs[key].([]string)
+		errs[key] = append(existing, msg)
+	}
+
+	for key, vals := range values {
+		if !strings.HasPrefix(key, "filter[") || len(vals) == 0 {
+			continue
+		}
+
+		name, op, ok := parseFilterKey(key)
+		if !ok {
+			addErr(key, "Invalid filter syntax, use filter[field] or filter[field][operator]")
+			continue
+		}
+
+		field, exists := schema.Fields[name]
+		if !exists || len(field.Operators) == 0 {
+			addErr(key, fmt.Sprintf("Unknown filter field: %s", name))
+			continue
+		}
+
+		if !allows(field.Operators, op) {
+			addErr(key, fmt.Sprintf("Operator %s is not allowed for %s", op, name))
+			continue
+		}
+
+		q.Conditions = append(q.Conditions, Condition{
+			Column:   field.Column,
+			Operator: op,
+			Value:    conditionValue(op, vals[0]),
+		})
+	}
+
+	sortParam := values.Get("sort")
+	if sortParam == "" {
+		sortParam = schema.DefaultSort
+	}
+
+	for _, part := range strings.Split(sortParam, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+
+		field, exists := schema.Fields[name]
+		if !exists || !field.Sortable {
+			addErr("sort", fmt.Sprintf("Unknown sort field: %s", name))
+			continue
+		}
+
+		q.Sorts = append(q.Sorts, Sort{Column: field.Column, Desc: desc})
+	}
+
+	tieBreaker := schema.TieBreaker
+	if tieBreaker == "" {
+		tieBreaker = "id"
+	}
+	if !sortsBy(q.Sorts, tieBreaker) {
+		q.Sorts = append(q.Sorts, Sort{Column: tieBreaker})
+	}
+
+	if len(errs) > 0 {
+		return Query{}, errs
+	}
+	return q, nil
+}
+
+// Scope menerapkan filter dan sort sekaligus
+func (q Query) Scope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(q.WhereScope(), q.OrderScope())
+	}
+}
+
+// WhereScope hanya menerapkan filter (berguna untuk cursor pagination yang punya urutan sendiri)
+func (q Query) WhereScope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, cond := range q.Conditions {
+			db = db.Where(cond.expression())
+		}
+		return db
+	}
+}
+
+// OrderScope hanya menerapkan sort
+func (q Query) OrderScope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, s := range q.Sorts {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.Column}, Desc: s.Desc})
+		}
+		return db
+	}
+}
+
+// expression memakai clause GORM agar nama kolom selalu di-quote dan nilai selalu di-bind
+func (cond Condition) expression() clause.Expression {
+	column := clause.Column{Name: cond.Column}
+
+	switch cond.Operator {
+	case OpNeq:
+		return clause.Neq{Column: column, Value: cond.Value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: cond.Value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: cond.Value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: cond.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: cond.Value}
+	case OpLike:
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []interface{}{column, cond.Value}}
+	case OpIn:
+		values, _ := cond.Value.([]interface{})
+		return clause.IN{Column: column, Values: values}
+	default:
+		return clause.Eq{Column: column, Value: cond.Value}
+	}
+}
+
+// parseFilterKey memecah "filter[field]" atau "filter[field][op]"
+func parseFilterKey(key string) (string, Operator, bool) {
+	inner := strings.TrimPrefix(key, "filter[")
+	if !strings.HasSuffix(inner, "]") {
+		return "", "", false
+	}
+	inner = strings.TrimSuffix(inner, "]")
+
+	parts := strings.Split(inner, "][")
+	switch len(parts) {
+	case 1:
+		return parts[0], OpEq, parts[0] != ""
+	case 2:
+		return parts[0], Operator(strings.ToLower(parts[1])), parts[0] != "" && parts[1] != ""
+	default:
+		return "", "", false
+	}
+}
+
+func conditionValue(op Operator, raw string) interface{} {
+	switch op {
+	case OpLike:
+		// % dan _ dari klien dicari apa adanya, bukan wildcard
+		return "%" + likeEscaper.Replace(raw) + "%"
+	case OpIn:
+		parts := strings.Split(raw, ",")
+		values := make([]interface{}, 0, len(parts))
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				values = append(values, p)
+			}
+		}
+		return values
+	default:
+		return raw
+	}
+}
+
+func sortsBy(sorts []Sort, column string) bool {
+	for _, s := range sorts {
+		if s.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+func allows(ops []Operator, op Operator) bool {
+	for _, allowed := range ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
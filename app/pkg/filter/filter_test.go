@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+var testSchema = Schema{
+	Fields: map[string]Field{
+		"id":    {Column: "id", Operators: []Operator{OpEq, OpIn}, Sortable: true},
+		"name":  {Column: "name", Operators: []Operator{OpEq, OpLike}, Sortable: true},
+		"email": {Column: "email_address", Operators: []Operator{OpLike}},
+	},
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name       string
+		query      string
+		conditions []Condition
+		sorts      []Sort
+		errKeys    []string
+	}{
+		{
+			name:  "default tiebreaker",
+			query: "",
+			sorts: []Sort{{Column: "id"}},
+		},
+		{
+			name:  "sort with tiebreaker appended",
+			query: "sort=-name",
+			sorts: []Sort{{Column: "name", Desc: true}, {Column: "id"}},
+		},
+		{
+			name:  "explicit id sort is not duplicated",
+			query: "sort=name,-id",
+			sorts: []Sort{{Column: "name"}, {Column: "id", Desc: true}},
+		},
+		{
+			name:       "like escapes wildcards",
+			query:      "filter[email][like]=" + url.QueryEscape("100%_a!"),
+			conditions: []Condition{{Column: "email_address", Operator: OpLike, Value: "%100!%!_a!!%"}},
+			sorts:      []Sort{{Column: "id"}},
+		},
+		{
+			name:       "in splits values",
+			query:      "filter[id][in]=1,2,,3",
+			conditions: []Condition{{Column: "id", Operator: OpIn, Value: []interface{}{"1", "2", "3"}}},
+			sorts:      []Sort{{Column: "id"}},
+		},
+		{name: "unknown field", query: "filter[password]=x", errKeys: []string{"filter[password]"}},
+		{name: "operator not allowed", query: "filter[name][gt]=a", errKeys: []string{"filter[name][gt]"}},
+		{name: "unsortable field", query: "sort=email", errKeys: []string{"sort"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			q, errs := Parse(values, testSchema)
+			if len(tc.errKeys) > 0 {
+				for _, key := range tc.errKeys {
+					if _, ok := errs[key]; !ok {
+						t.Errorf("missing error for %s in %v", key, errs)
+					}
+				}
+				return
+			}
+			if errs != nil {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !reflect.DeepEqual(q.Conditions, tc.conditions) {
+				t.Errorf("conditions = %#v, want %#v", q.Conditions, tc.conditions)
+			}
+			if !reflect.DeepEqual(q.Sorts, tc.sorts) {
+				t.Errorf("sorts = %#v, want %#v", q.Sorts, tc.sorts)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"response-std/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thedevsaddam/govalidator"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	DefaultLocale  = "id"
+	FallbackLocale = "en"
+
+	localeKey = "i18n.locale"
+)
+
+// Katalog bawaan (lang/en.json, lang/id.json) selalu ikut di binary,
+// LANG_DIR hanya menimpa/menambah key.
+//
+//go:embed lang/*.json
+var embedded embed.FS
+
+var (
+	catalogs      = make(map[string]map[string]string)
+	catalogsMutex sync.RWMutex
+)
+
+// LocalePreferrer diimplementasikan user yang menyimpan preferensi bahasa (lihat entities.User)
+type LocalePreferrer interface {
+	PreferredLocale() string
+}
+
+func init() {
+	if err := loadFS(embedded, "lang"); err != nil {
+		panic(fmt.Errorf("i18n: failed to load embedded catalogs: %w", err))
+	}
+}
+
+// Load membaca semua file <locale>.json / <locale>.yaml / <locale>.yml di dir.
+// Key dari file di-merge ke katalog yang sudah ada (file menang jika bentrok).
+func Load(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	return loadFS(os.DirFS(dir), ".")
+}
+
+func loadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		raw, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return err
+		}
+
+		var tree map[string]interface{}
+		if ext == ".json" {
+			err = json.Unmarshal(raw, &tree)
+		} else {
+			err = yaml.Unmarshal(raw, &tree)
+		}
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %w", entry.Name(), err)
+		}
+
+		messages := make(map[string]string)
+		flatten("", tree, messages)
+		AddMessages(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), messages)
+	}
+
+	return nil
+}
+
+// flatten mengubah {"auth": {"login_success": "..."}} menjadi {"auth.login_success": "..."}
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch value := v.(type) {
+		case map[string]interface{}:
+			flatten(key, value, out)
+		case string:
+			out[key] = value
+		default:
+			out[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// AddMessages menambahkan key ke katalog locale (dipakai Load, atau langsung dari kode)
+func AddMessages(locale string, messages map[string]string) {
+	locale = normalize(locale)
+
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		catalogs[locale] = catalog
+	}
+	for k, v := range messages {
+		catalog[k] = v
+	}
+}
+
+// Locales mengembalikan daftar locale yang punya katalog
+func Locales() []string {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	list := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		list = append(list, locale)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Has true jika key ada di katalog locale atau fallback locale
+func Has(locale, key string) bool {
+	_, ok := lookup(locale, key)
+	return ok
+}
+
+// Translate menerjemahkan key ke locale. Urutan: locale -> fallback locale -> key apa adanya,
+// sehingga pesan biasa (bukan key) tetap aman dilewatkan ke sini.
+// args diteruskan ke fmt.Sprintf jika ada.
+func Translate(locale, key string, args ...interface{}) string {
+	message, ok := lookup(locale, key)
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// T menerjemahkan key ke locale request (lihat Locale)
+func T(c *gin.Context, key string, args ...interface{}) string {
+	return Translate(Locale(c), key, args...)
+}
+
+// Messages menerjemahkan pesan govalidator berformat "rule:key" sesuai locale request
+// contoh penggunaan:
+//
+//	messages := i18n.Messages(c, govalidator.MapData{
+//	    "email": []string{"required:validation.email.required"},
+//	})
+func Messages(c *gin.Context, messages govalidator.MapData) govalidator.MapData {
+	locale := Locale(c)
+
+	translated := make(govalidator.MapData, len(messages))
+	for field, list := range messages {
+		out := make([]string, len(list))
+		for i, item := range list {
+			rule, key, found := strings.Cut(item, ":")
+			if !found {
+				out[i] = item
+				continue
+			}
+			out[i] = rule + ":" + Translate(locale, key)
+		}
+		translated[field] = out
+	}
+	return translated
+}
+
+// SetLocale memaksa locale untuk request ini
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(localeKey, normalize(locale))
+}
+
+// Locale menentukan bahasa respons. Urutan:
+// SetLocale -> ?lang= -> preferensi user login -> header Accept-Language -> DEFAULT_LOCALE.
+// Locale yang tidak punya katalog dilewati.
+func Locale(c *gin.Context) string {
+	if c == nil {
+		return defaultLocale()
+	}
+
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+
+	if locale, ok := Supported(c.Query("lang")); ok {
+		return locale
+	}
+
+	if user, exists := c.Get("user"); exists {
+		if pref, ok := user.(LocalePreferrer); ok {
+			if locale, ok := Supported(pref.PreferredLocale()); ok {
+				return locale
+			}
+		}
+	}
+
+	for _, tag := range parseAcceptLanguage(c.GetHeader("Accept-Language")) {
+		if locale, ok := Supported(tag); ok {
+			return locale
+		}
+	}
+
+	return defaultLocale()
+}
+
+func lookup(locale, key string) (string, bool) {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	if message, ok := catalogs[normalize(locale)][key]; ok {
+		return message, true
+	}
+	if message, ok := catalogs[fallbackLocale()][key]; ok {
+		return message, true
+	}
+	return "", false
+}
+
+// Supported mencocokkan tag (mis. "id-ID") ke locale yang punya katalog: persis dulu, lalu bahasa dasarnya ("id")
+func Supported(tag string) (string, bool) {
+	tag = normalize(tag)
+	if tag == "" {
+		return "", false
+	}
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	if _, ok := catalogs[tag]; ok {
+		return tag, true
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if _, ok := catalogs[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// parseAcceptLanguage mengembalikan language tag dari header Accept-Language, diurutkan berdasarkan q
+func parseAcceptLanguage(header string) []string {
+	type languageRange struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > 0 {
+			ranges = append(ranges, languageRange{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	list := make([]string, len(ranges))
+	for i, r := range ranges {
+		list[i] = r.tag
+	}
+	return list
+}
+
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func defaultLocale() string {
+	if config.ENV != nil && config.ENV.DefaultLocale != "" {
+		return normalize(config.ENV.DefaultLocale)
+	}
+	return DefaultLocale
+}
+
+func fallbackLocale() string {
+	if config.ENV != nil && config.ENV.FallbackLocale != "" {
+		return normalize(config.ENV.FallbackLocale)
+	}
+	return FallbackLocale
+}
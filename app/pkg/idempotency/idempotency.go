@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"response-std/app/models/entities"
+	"response-std/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// MaxKeyLength membatasi panjang header; klien biasanya mengirim UUID
+	MaxKeyLength = 255
+
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultLockTimeout adalah batas reservasi selama request pertama diproses
+	DefaultLockTimeout = time.Minute
+)
+
+// ReplayHeaders adalah header respons yang ikut disimpan & di-replay.
+// Header per-request (X-Request-ID, X-RateLimit-*) sengaja tidak disimpan.
+var ReplayHeaders = []string{"Content-Type", "Content-Language", "Location", "ETag"}
+
+var (
+	defaultStore Store = NewMemoryStore()
+	defaultTTL         = DefaultTTL
+	defaultLock        = DefaultLockTimeout
+	storeMutex   sync.RWMutex
+)
+
+// Init memilih store dari IDEMPOTENCY_STORE (memory | database), TTL dari IDEMPOTENCY_TTL
+// dan batas lock dari IDEMPOTENCY_LOCK_TIMEOUT
+func Init(cfg *config.Config, db *gorm.DB) {
+	var store Store = NewMemoryStore()
+	if strings.ToLower(cfg.IdempotencyStore) == "database" && db != nil {
+		store = NewGormStore(db)
+	}
+
+	ttl := cfg.IdempotencyTTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	// Lock tidak boleh habis sebelum handler selesai, kalau tidak request kedua ikut memproses
+	lock := cfg.IdempotencyLockTimeout
+	if lock <= 0 {
+		lock = DefaultLockTimeout
+	}
+	if lock <= cfg.HandlerTimeout {
+		lock = cfg.HandlerTimeout + 10*time.Second
+	}
+
+	storeMutex.Lock()
+	defaultStore = store
+	defaultTTL = ttl
+	defaultLock = lock
+	storeMutex.Unlock()
+}
+
+// SetStore mengganti store default (mis. untuk store custom)
+func SetStore(s Store) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	defaultStore = s
+}
+
+// Default mengembalikan store, TTL replay dan batas lock yang dipakai middleware
+func Default() (Store, time.Duration, time.Duration) {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	return defaultStore, defaultTTL, defaultLock
+}
+
+// StorageKey membentuk key penyimpanan dari header Idempotency-Key, di-scope per user
+// (atau IP untuk request tanpa login) dan per route, lalu di-hash agar panjangnya tetap.
+func StorageKey(c *gin.Context, key string) string {
+	scope := "guest:" + c.ClientIP()
+	if value, exists := c.Get("user"); exists {
+		if user, ok := value.(entities.User); ok {
+			scope = "user:" + strconv.FormatUint(uint64(user.ID), 10)
+		}
+	}
+	return hash(scope, c.Request.Method+" "+c.FullPath(), key)
+}
+
+// Fingerprint mengidentifikasi payload request; key yang sama dengan payload berbeda ditolak
+func Fingerprint(c *gin.Context, body []byte) string {
+	return hash(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+}
+
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,204 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"response-std/app/models/entities"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Record adalah status satu Idempotency-Key. StatusCode 0 berarti request pertama masih diproses;
+// selama itu ExpiresAt adalah batas lock (bukan TTL replay), sehingga reservasi yang ditinggal
+// (proses crash sebelum Complete / Release) bisa diambil alih request berikutnya.
+type Record struct {
+	Fingerprint string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Completed true jika respons sudah tersimpan dan bisa di-replay
+func (r Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store menyimpan Record per key dengan TTL.
+// Reserve harus atomik: hanya satu request yang mendapat reserved=true untuk key yang sama.
+type Store interface {
+	// Reserve menandai key sedang diproses selama lock. Jika key sudah ada dan belum expired, Record yang
+	// ada dikembalikan dengan reserved=false; record expired (termasuk lock yang basi) diambil alih.
+	Reserve(ctx context.Context, key, fingerprint string, lock time.Duration) (existing Record, reserved bool, err error)
+	// Complete menyimpan respons untuk key yang sudah di-reserve; rec.ExpiresAt menjadi batas replay
+	Complete(ctx context.Context, key string, rec Record) error
+	// Release menghapus reservasi (handler gagal / 5xx) agar klien bisa retry dengan key yang sama
+	Release(ctx context.Context, key string) error
+}
+
+// ---------------------------
+// MEMORY STORE
+// ---------------------------
+// MemoryStore cocok untuk satu instance; isinya hilang saat restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key, fingerprint string, lock time.Duration) (Record, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Bersihkan record yang sudah lewat paling sering sekali per menit
+	if now.After(s.nextSweep) {
+		for k, rec := range s.records {
+			if now.After(rec.ExpiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+
+	if rec, ok := s.records[key]; ok && now.Before(rec.ExpiresAt) {
+		return rec, false, nil
+	}
+
+	s.records[key] = Record{Fingerprint: fingerprint, ExpiresAt: now.Add(lock)}
+	return Record{}, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok && rec.ExpiresAt.IsZero() {
+		rec.ExpiresAt = existing.ExpiresAt
+	}
+	s.records[key] = rec
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// ---------------------------
+// DATABASE STORE (tabel idempotency_keys)
+// ---------------------------
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Reserve(ctx context.Context, key, fingerprint string, lock time.Duration) (Record, bool, error) {
+	now := time.Now()
+	row := entities.IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(lock),
+		CreatedAt:   now,
+	}
+
+	// Primary key menjamin hanya satu INSERT yang berhasil untuk key yang sama
+	err := s.db.WithContext(ctx).Create(&row).Error
+	if err == nil {
+		return Record{}, true, nil
+	}
+	if !isDuplicate(err) {
+		return Record{}, false, err
+	}
+
+	var existing entities.IdempotencyKey
+	if err := s.db.WithContext(ctx).First(&existing, "`key` = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Baru saja di-release oleh request lain, coba reserve ulang
+			return s.Reserve(ctx, key, fingerprint, lock)
+		}
+		return Record{}, false, err
+	}
+
+	if now.After(existing.ExpiresAt) {
+		// Expired / lock basi: hapus lalu reserve ulang. Kondisi expires_at memastikan hanya satu
+		// request yang mengambil alih jika beberapa melihat record basi yang sama.
+		if err := s.db.WithContext(ctx).Where("`key` = ? AND expires_at = ?", key, existing.ExpiresAt).
+			Delete(&entities.IdempotencyKey{}).Error; err != nil {
+			return Record{}, false, err
+		}
+		return s.Reserve(ctx, key, fingerprint, lock)
+	}
+
+	return toRecord(existing)
+}
+
+func (s *GormStore) Complete(ctx context.Context, key string, rec Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	headerJSON := string(header)
+	now := time.Now()
+
+	updates := map[string]interface{}{
+		"status_code":      rec.StatusCode,
+		"response_headers": &headerJSON,
+		"response_body":    rec.Body,
+		"completed_at":     &now,
+	}
+	if !rec.ExpiresAt.IsZero() {
+		updates["expires_at"] = rec.ExpiresAt
+	}
+
+	return s.db.WithContext(ctx).Model(&entities.IdempotencyKey{}).
+		Where("`key` = ?", key).
+		Updates(updates).Error
+}
+
+func (s *GormStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("`key` = ?", key).Delete(&entities.IdempotencyKey{}).Error
+}
+
+// Prune menghapus key yang sudah expired, bisa dipanggil dari cron / console command
+func (s *GormStore) Prune(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entities.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+func toRecord(row entities.IdempotencyKey) (Record, bool, error) {
+	rec := Record{
+		Fingerprint: row.Fingerprint,
+		StatusCode:  row.StatusCode,
+		Body:        row.ResponseBody,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.ResponseHeaders != nil && *row.ResponseHeaders != "" {
+		if err := json.Unmarshal([]byte(*row.ResponseHeaders), &rec.Header); err != nil {
+			return Record{}, false, err
+		}
+	}
+	return rec, false, nil
+}
+
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &mysqlErr) && mysqlErr.Number == 1062)
+}
@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"response-std/app/models/entities"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entities.IdempotencyKey{}); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Store{
+		"memory":   NewMemoryStore(),
+		"database": NewGormStore(db),
+	}
+}
+
+func TestStoreInFlightLock(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, reserved, err := store.Reserve(ctx, "k1", "fp", time.Minute); err != nil || !reserved {
+				t.Fatalf("first reserve = %v, %v; want reserved", reserved, err)
+			}
+			existing, reserved, err := store.Reserve(ctx, "k1", "fp", time.Minute)
+			if err != nil || reserved || existing.Completed() {
+				t.Fatalf("second reserve = %+v, %v, %v; want in-flight record", existing, reserved, err)
+			}
+
+			// Reservasi yang ditinggal (crash sebelum Complete) basi setelah lock habis
+			if _, reserved, err := store.Reserve(ctx, "k2", "fp", -time.Second); err != nil || !reserved {
+				t.Fatalf("reserve k2 = %v, %v; want reserved", reserved, err)
+			}
+			if _, reserved, err := store.Reserve(ctx, "k2", "fp", time.Minute); err != nil || !reserved {
+				t.Errorf("stale lock reserve = %v, %v; want takeover", reserved, err)
+			}
+		})
+	}
+}
+
+func TestStoreCompleteExtendsExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.Reserve(ctx, "k1", "fp", time.Millisecond)
+			err := store.Complete(ctx, "k1", Record{
+				Fingerprint: "fp",
+				StatusCode:  http.StatusCreated,
+				Header:      http.Header{"Content-Type": {"application/json"}},
+				Body:        []byte(`{}`),
+				ExpiresAt:   time.Now().Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			existing, reserved, err := store.Reserve(ctx, "k1", "fp", time.Minute)
+			if err != nil || reserved {
+				t.Fatalf("reserve after complete = %v, %v; want stored record", reserved, err)
+			}
+			if existing.StatusCode != http.StatusCreated || string(existing.Body) != `{}` {
+				t.Errorf("record = %d %s, want 201 {}", existing.StatusCode, existing.Body)
+			}
+		})
+	}
+}
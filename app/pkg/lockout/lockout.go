@@ -0,0 +1,295 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"response-std/config"
+	"response-std/libs/external/services"
+	"response-std/libs/external/services/hooks"
+	"response-std/libs/requestid"
+)
+
+// Scope menunjukkan key mana yang memicu keputusan
+const (
+	ScopeUsername = "username"
+	ScopeIP       = "ip"
+)
+
+// Settings mengatur batas percobaan login. Nilai 0 diganti default (lihat withDefaults).
+type Settings struct {
+	MaxAttempts      int64         // gagal per username sebelum lockout
+	MaxAttemptsPerIP int64         // gagal per IP sebelum lockout
+	Window           time.Duration // umur counter gagal
+	LockoutDuration  time.Duration
+	BaseDelay        time.Duration // delay progresif: BaseDelay * 2^(gagal-1)
+	MaxDelay         time.Duration
+}
+
+func (s Settings) withDefaults() Settings {
+	if s.MaxAttempts <= 0 {
+		s.MaxAttempts = 5
+	}
+	if s.MaxAttemptsPerIP <= 0 {
+		s.MaxAttemptsPerIP = 20
+	}
+	if s.Window <= 0 {
+		s.Window = 15 * time.Minute
+	}
+	if s.LockoutDuration <= 0 {
+		s.LockoutDuration = 15 * time.Minute
+	}
+	if s.BaseDelay <= 0 {
+		s.BaseDelay = time.Second
+	}
+	if s.MaxDelay <= 0 {
+		s.MaxDelay = 30 * time.Second
+	}
+	return s
+}
+
+// unavailableRetry adalah Retry-After saat store tidak bisa dibaca
+const unavailableRetry = 5 * time.Second
+
+// Decision adalah hasil pengecekan; Locked -> 423, Throttled -> 429, Unavailable -> 503
+type Decision struct {
+	Locked      bool
+	Throttled   bool
+	Unavailable bool // store error: login ditolak (fail closed)
+	Scope       string
+	RetryAfter  time.Duration
+}
+
+func (d Decision) Allowed() bool {
+	return !d.Locked && !d.Throttled && !d.Unavailable
+}
+
+// Guard melacak login gagal per username dan per IP
+type Guard struct {
+	store    Store
+	settings Settings
+}
+
+func NewGuard(store Store, settings Settings) *Guard {
+	return &Guard{store: store, settings: settings.withDefaults()}
+}
+
+var (
+	defaultGuard = NewGuard(NewMemoryStore(), Settings{})
+	guardMutex   sync.RWMutex
+)
+
+// Init membuat guard default dari config (store mengikuti RATE_LIMIT_STORE)
+func Init(cfg *config.Config) {
+	var store Store = NewMemoryStore()
+	if strings.ToLower(cfg.RateLimitStore) == "redis" {
+		store = NewRedisStore(config.RedisClient(), "lockout:")
+	}
+
+	guard := NewGuard(store, Settings{
+		MaxAttempts:      cfg.LoginMaxAttempts,
+		MaxAttemptsPerIP: cfg.LoginMaxAttemptsPerIP,
+		Window:           cfg.LoginAttemptWindow,
+		LockoutDuration:  cfg.LoginLockoutDuration,
+		BaseDelay:        cfg.LoginDelayBase,
+		MaxDelay:         cfg.LoginDelayMax,
+	})
+
+	guardMutex.Lock()
+	defaultGuard = guard
+	guardMutex.Unlock()
+}
+
+// Default mengembalikan guard yang dipakai AuthController
+func Default() *Guard {
+	guardMutex.RLock()
+	defer guardMutex.RUnlock()
+	return defaultGuard
+}
+
+// Check dipanggil sebelum password diverifikasi.
+// Fail closed: jika store error login ditolak (Unavailable), karena meloloskan request berarti
+// brute force tidak terbatas selama store mati.
+func (g *Guard) Check(ctx context.Context, username, ip string) Decision {
+	now := time.Now()
+
+	for _, target := range g.targets(username, ip) {
+		rec, err := g.store.Get(ctx, target.key)
+		if err != nil {
+			storeError(ctx, "check", err)
+			return Decision{Unavailable: true, RetryAfter: unavailableRetry}
+		}
+
+		if rec.LockedUntil.After(now) {
+			return Decision{Locked: true, Scope: target.scope, RetryAfter: rec.LockedUntil.Sub(now)}
+		}
+
+		// Delay progresif hanya per username agar user lain di balik NAT yang sama tidak ikut tertahan
+		if target.scope == ScopeUsername && rec.Attempts > 0 {
+			if next := rec.LastFailure.Add(g.delay(rec.Attempts)); next.After(now) {
+				return Decision{Throttled: true, Scope: target.scope, RetryAfter: next.Sub(now)}
+			}
+		}
+	}
+
+	return Decision{}
+}
+
+// Fail mencatat login gagal secara atomik di store. Decision.Locked true jika percobaan ini
+// memicu lockout. Jika store error, percobaan tidak tercatat dan Decision.Unavailable true
+// (login gagal tetap dijawab gagal; Check berikutnya juga menolak selama store error).
+func (g *Guard) Fail(ctx context.Context, username, ip string) Decision {
+	now := time.Now()
+	ttl := g.settings.Window
+	if g.settings.LockoutDuration > ttl {
+		ttl = g.settings.LockoutDuration
+	}
+
+	var decision Decision
+	for _, target := range g.targets(username, ip) {
+		rec, locked, err := g.store.Increment(ctx, target.key, Failure{
+			At:      now,
+			Max:     target.max,
+			Lockout: g.settings.LockoutDuration,
+			TTL:     ttl,
+		})
+		if err != nil {
+			storeError(ctx, "fail", err)
+			if !decision.Locked {
+				decision = Decision{Unavailable: true, RetryAfter: unavailableRetry}
+			}
+			continue
+		}
+
+		if locked {
+			if !decision.Locked {
+				decision = Decision{Locked: true, Scope: target.scope, RetryAfter: g.settings.LockoutDuration}
+			}
+
+			securityEvent(ctx, "warn", "auth.lockout", "Login locked after too many failed attempts", map[string]interface{}{
+				"scope":        target.scope,
+				"username":     username,
+				"client_ip":    ip,
+				"attempts":     rec.Attempts,
+				"locked_until": rec.LockedUntil.Format(time.RFC3339),
+			})
+		}
+
+		if target.scope == ScopeUsername && !decision.Locked {
+			securityEvent(ctx, "info", "auth.login_failed", "Failed login attempt", map[string]interface{}{
+				"username":  username,
+				"client_ip": ip,
+				"attempts":  rec.Attempts,
+			})
+		}
+	}
+
+	return decision
+}
+
+// Succeed mereset counter username setelah login berhasil.
+// Counter IP sengaja tidak direset agar satu akun valid tidak bisa dipakai untuk
+// menghapus jejak brute force dari IP yang sama.
+func (g *Guard) Succeed(ctx context.Context, username string) {
+	if err := g.store.Delete(ctx, usernameKey(username)); err != nil {
+		storeError(ctx, "succeed", err)
+	}
+}
+
+// Unlock menghapus lockout & counter untuk username dan/atau IP (dipakai admin)
+func (g *Guard) Unlock(ctx context.Context, username, ip string) error {
+	for _, target := range g.targets(username, ip) {
+		if err := g.store.Delete(ctx, target.key); err != nil {
+			return err
+		}
+	}
+
+	securityEvent(ctx, "info", "auth.unlock", "Login lockout cleared", map[string]interface{}{
+		"username":  username,
+		"client_ip": ip,
+	})
+	return nil
+}
+
+// Status mengembalikan record untuk username dan IP (dipakai admin untuk melihat lockout)
+func (g *Guard) Status(ctx context.Context, username, ip string) (map[string]Record, error) {
+	status := make(map[string]Record)
+	for _, target := range g.targets(username, ip) {
+		rec, err := g.store.Get(ctx, target.key)
+		if err != nil {
+			return nil, err
+		}
+		status[target.scope] = rec
+	}
+	return status, nil
+}
+
+func (g *Guard) delay(attempts int64) time.Duration {
+	d := g.settings.BaseDelay
+	for i := int64(1); i < attempts && d < g.settings.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > g.settings.MaxDelay {
+		d = g.settings.MaxDelay
+	}
+	return d
+}
+
+type target struct {
+	scope string
+	key   string
+	max   int64
+}
+
+func (g *Guard) targets(username, ip string) []target {
+	var list []target
+	if username = normalizeUsername(username); username != "" {
+		list = append(list, target{scope: ScopeUsername, key: usernameKey(username), max: g.settings.MaxAttempts})
+	}
+	if ip != "" {
+		list = append(list, target{scope: ScopeIP, key: "ip:" + ip, max: g.settings.MaxAttemptsPerIP})
+	}
+	return list
+}
+
+func usernameKey(username string) string {
+	return "username:" + normalizeUsername(username)
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// securityEvent menulis event keamanan terstruktur lewat services.Logger.
+// Event level warn juga dikirim ke Discord walaupun DISCORD_MIN_LOG_LEVEL lebih tinggi.
+func securityEvent(ctx context.Context, level, event, message string, fields map[string]interface{}) {
+	fields["event"] = event
+	fields["category"] = "security"
+
+	if services.AppLogger != nil {
+		logger := services.AppLogger.WithContext(ctx)
+		if level == "warn" {
+			logger.Warn("[SECURITY] "+message, fields)
+		} else {
+			logger.Info("[SECURITY] "+message, fields)
+		}
+	}
+
+	if id := requestid.FromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+
+	if level == "warn" && config.ENV != nil && config.ENV.DiscordWebhookURL != "" && !config.ENV.ShouldLogToDiscord(level) {
+		go hooks.SendDiscordMessage(config.ENV.DiscordWebhookURL, config.ENV.APP_NAME, level, "[SECURITY] "+message, fields)
+	}
+}
+
+func storeError(ctx context.Context, op string, err error) {
+	if services.AppLogger != nil {
+		services.AppLogger.WithContext(ctx).Error("Login lockout store unavailable, login rejected", err, map[string]interface{}{
+			"operation": op,
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingStore mensimulasikan store yang mati (mis. Redis down)
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) (Record, error) {
+	return Record{}, errors.New("store down")
+}
+
+func (failingStore) Increment(context.Context, string, Failure) (Record, bool, error) {
+	return Record{}, false, errors.New("store down")
+}
+
+func (failingStore) Delete(context.Context, string) error {
+	return errors.New("store down")
+}
+
+func newTestGuard(store Store) *Guard {
+	return NewGuard(store, Settings{
+		MaxAttempts:      3,
+		MaxAttemptsPerIP: 10,
+		LockoutDuration:  time.Minute,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         time.Millisecond,
+	})
+}
+
+func TestGuardLocksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGuard(NewMemoryStore())
+
+	var last Decision
+	for i := 0; i < 3; i++ {
+		last = g.Fail(ctx, "Alice", "10.0.0.1")
+	}
+	if !last.Locked || last.Scope != ScopeUsername {
+		t.Fatalf("third failure = %+v, want locked by username", last)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	d := g.Check(ctx, " alice ", "10.0.0.2")
+	if !d.Locked || d.RetryAfter <= 0 {
+		t.Errorf("check = %+v, want locked with retry-after (username is normalized)", d)
+	}
+
+	if err := g.Unlock(ctx, "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if d := g.Check(ctx, "alice", "10.0.0.2"); !d.Allowed() {
+		t.Errorf("check after unlock = %+v, want allowed", d)
+	}
+}
+
+func TestGuardThrottlesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	g := NewGuard(NewMemoryStore(), Settings{BaseDelay: time.Minute, MaxDelay: time.Minute})
+
+	g.Fail(ctx, "bob", "10.0.0.1")
+	d := g.Check(ctx, "bob", "10.0.0.1")
+	if !d.Throttled || d.Scope != ScopeUsername {
+		t.Fatalf("check = %+v, want throttled by username", d)
+	}
+
+	// Delay hanya per username, user lain dari IP yang sama tidak tertahan
+	if d := g.Check(ctx, "carol", "10.0.0.1"); !d.Allowed() {
+		t.Errorf("other user = %+v, want allowed", d)
+	}
+}
+
+func TestGuardSucceedResetsUsernameOnly(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	g := newTestGuard(store)
+
+	g.Fail(ctx, "dave", "10.0.0.1")
+	g.Succeed(ctx, "dave")
+
+	status, err := g.Status(ctx, "dave", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[ScopeUsername].Attempts != 0 {
+		t.Errorf("username attempts = %d, want 0", status[ScopeUsername].Attempts)
+	}
+	if status[ScopeIP].Attempts != 1 {
+		t.Errorf("ip attempts = %d, want 1", status[ScopeIP].Attempts)
+	}
+}
+
+func TestGuardFailsClosedOnStoreError(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGuard(failingStore{})
+
+	if d := g.Check(ctx, "eve", "10.0.0.1"); d.Allowed() || !d.Unavailable || d.RetryAfter <= 0 {
+		t.Errorf("check = %+v, want unavailable with retry-after", d)
+	}
+	if d := g.Fail(ctx, "eve", "10.0.0.1"); !d.Unavailable {
+		t.Errorf("fail = %+v, want unavailable", d)
+	}
+}
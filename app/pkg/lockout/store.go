@@ -0,0 +1,168 @@
+package lockout
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Record adalah status percobaan login gagal untuk satu key (username atau IP)
+type Record struct {
+	Attempts    int64     `json:"attempts"`
+	LastFailure time.Time `json:"last_failure"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// Failure adalah satu login gagal yang dicatat lewat Store.Increment
+type Failure struct {
+	At      time.Time
+	Max     int64         // attempts >= Max memicu lockout
+	Lockout time.Duration // lama lockout
+	TTL     time.Duration // umur record sejak gagal terakhir
+}
+
+// Store menyimpan Record dengan TTL. Increment wajib atomik: login gagal paralel untuk key
+// yang sama tidak boleh saling menimpa counter.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error)
+	// Increment menambah attempts, mengisi last_failure, dan mengunci key jika attempts mencapai
+	// Max dan key belum terkunci. locked true hanya untuk percobaan yang memicu lockout.
+	Increment(ctx context.Context, key string, f Failure) (rec Record, locked bool, err error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ---------------------------
+// MEMORY STORE
+// ---------------------------
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+}
+
+type memoryRecord struct {
+	rec       Record
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(key), nil
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, f Failure) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := s.get(key)
+	rec.Attempts++
+	rec.LastFailure = f.At
+
+	locked := false
+	if rec.Attempts >= f.Max && !rec.LockedUntil.After(f.At) {
+		rec.LockedUntil = f.At.Add(f.Lockout)
+		locked = true
+	}
+
+	s.records[key] = memoryRecord{rec: rec, expiresAt: f.At.Add(f.TTL)}
+	return rec, locked, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// get harus dipanggil dengan s.mu terkunci
+func (s *MemoryStore) get(key string) Record {
+	item, ok := s.records[key]
+	if !ok {
+		return Record{}
+	}
+	if time.Now().After(item.expiresAt) {
+		delete(s.records, key)
+		return Record{}
+	}
+	return item.rec
+}
+
+// ---------------------------
+// REDIS STORE
+// ---------------------------
+// Record disimpan sebagai hash (attempts, last_failure, locked_until dalam unix ms).
+// HINCRBY + cek lockout + PEXPIRE dijalankan atomik dalam satu script.
+var incrementScript = redis.NewScript(`
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+local now = tonumber(ARGV[1])
+redis.call("HSET", KEYS[1], "last_failure", now)
+local locked_until = tonumber(redis.call("HGET", KEYS[1], "locked_until") or "0")
+local locked = 0
+if attempts >= tonumber(ARGV[2]) and locked_until <= now then
+  locked_until = now + tonumber(ARGV[3])
+  redis.call("HSET", KEYS[1], "locked_until", locked_until)
+  locked = 1
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {attempts, locked_until, locked}
+`)
+
+// RedisStore berbagi counter antar instance
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Record, error) {
+	fields, err := s.client.HGetAll(ctx, s.prefix+key).Result()
+	if err != nil {
+		return Record{}, err
+	}
+
+	attempts, _ := strconv.ParseInt(fields["attempts"], 10, 64)
+	return Record{
+		Attempts:    attempts,
+		LastFailure: fromMillis(fields["last_failure"]),
+		LockedUntil: fromMillis(fields["locked_until"]),
+	}, nil
+}
+
+func (s *RedisStore) Increment(ctx context.Context, key string, f Failure) (Record, bool, error) {
+	result, err := incrementScript.Run(ctx, s.client, []string{s.prefix + key},
+		f.At.UnixMilli(), f.Max, f.Lockout.Milliseconds(), f.TTL.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	rec := Record{Attempts: result[0], LastFailure: time.UnixMilli(f.At.UnixMilli())}
+	if result[1] > 0 {
+		rec.LockedUntil = time.UnixMilli(result[1])
+	}
+	return rec, result[2] == 1, nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+func fromMillis(raw string) time.Time {
+	ms, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,141 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStore menjalankan skenario yang sama untuk setiap implementasi Store
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	failure := func(max int64) Failure {
+		return Failure{At: time.Now(), Max: max, Lockout: time.Minute, TTL: time.Minute}
+	}
+
+	cases := []struct {
+		name       string
+		key        string
+		fails      int
+		max        int64
+		wantCount  int64
+		wantLocked bool
+	}{
+		{"below threshold", "a", 2, 5, 2, false},
+		{"reaches threshold", "b", 3, 3, 3, true},
+		{"keeps counting while locked", "c", 4, 3, 4, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < tc.fails; i++ {
+				if _, _, err := s.Increment(ctx, tc.key, failure(tc.max)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec, err := s.Get(ctx, tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Attempts != tc.wantCount {
+				t.Errorf("attempts = %d, want %d", rec.Attempts, tc.wantCount)
+			}
+			if locked := rec.LockedUntil.After(time.Now()); locked != tc.wantLocked {
+				t.Errorf("locked = %v, want %v", locked, tc.wantLocked)
+			}
+			if rec.LastFailure.IsZero() {
+				t.Error("last_failure not recorded")
+			}
+		})
+	}
+
+	t.Run("delete clears record", func(t *testing.T) {
+		if err := s.Delete(ctx, "b"); err != nil {
+			t.Fatal(err)
+		}
+		rec, err := s.Get(ctx, "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Attempts != 0 || !rec.LockedUntil.IsZero() {
+			t.Errorf("record after delete = %+v, want empty", rec)
+		}
+	})
+
+	t.Run("concurrent failures are not lost and lock once", func(t *testing.T) {
+		const workers = 50
+		var locks int32
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, locked, err := s.Increment(ctx, "parallel", failure(10))
+				if err != nil {
+					t.Error(err)
+				}
+				if locked {
+					atomic.AddInt32(&locks, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		rec, err := s.Get(ctx, "parallel")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Attempts != workers {
+			t.Errorf("attempts = %d, want %d", rec.Attempts, workers)
+		}
+		if locks != 1 {
+			t.Errorf("lockout triggered %d times, want 1", locks)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	if _, _, err := s.Increment(ctx, "k", Failure{At: time.Now(), Max: 5, Lockout: time.Minute, TTL: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	rec, _ := s.Get(ctx, "k")
+	if rec.Attempts != 0 {
+		t.Errorf("attempts after ttl = %d, want 0", rec.Attempts)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	testStore(t, NewRedisStore(client, "test:"))
+
+	if !mr.Exists("test:a") {
+		t.Fatal("expected prefixed key test:a in redis")
+	}
+	if ttl := mr.TTL("test:a"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("ttl = %v, want within (0, 1m]", ttl)
+	}
+
+	// miniredis tidak menjalankan TTL sendiri; FastForward memajukan waktunya
+	mr.FastForward(2 * time.Minute)
+	if mr.Exists("test:a") {
+		t.Error("record should expire after ttl")
+	}
+}
@@ -0,0 +1,180 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"response-std/libs/responses"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 15
+	MaxLimit     = 100
+)
+
+// Mode pagination (?mode=)
+const (
+	ModeOffset = "offset"
+	ModeCursor = "cursor"
+)
+
+// ErrInvalidCursor dikembalikan jika parameter cursor tidak bisa di-decode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params adalah parameter pagination hasil parsing query string
+// ?page=2&limit=20 (offset) atau ?mode=cursor&limit=20 lalu ?cursor=xxx&limit=20 (cursor)
+type Params struct {
+	Page   int
+	Limit  int
+	Cursor string
+	Mode   string
+}
+
+// Offset mengembalikan offset SQL untuk page saat ini
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// IsCursor true jika klien meminta cursor-based pagination. Halaman pertama diminta dengan
+// ?mode=cursor atau ?cursor= (kosong); halaman berikutnya memakai next_cursor.
+func (p Params) IsCursor() bool {
+	return p.Mode == ModeCursor
+}
+
+// FromQuery membaca page, limit dan cursor dari query string dengan DefaultLimit & MaxLimit
+func FromQuery(c *gin.Context) Params {
+	return FromQueryWithLimits(c, DefaultLimit, MaxLimit)
+}
+
+// FromQueryWithLimits sama seperti FromQuery tapi dengan default & batas limit sendiri.
+// Nilai yang tidak valid dikembalikan ke default, limit di atas maxLimit dipotong.
+func FromQueryWithLimits(c *gin.Context, defaultLimit, maxLimit int) Params {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	cursor, hasCursor := c.GetQuery("cursor")
+	mode := ModeOffset
+	if hasCursor || c.Query("mode") == ModeCursor {
+		mode = ModeCursor
+	}
+
+	return Params{
+		Page:   page,
+		Limit:  limit,
+		Cursor: cursor,
+		Mode:   mode,
+	}
+}
+
+// Scope menerapkan offset/limit ke query
+// contoh penggunaan:
+// db.Scopes(pagination.Scope(params)).Find(&users)
+func Scope(params Params) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(params.Offset()).Limit(params.Limit)
+	}
+}
+
+// Paginate menghitung total lalu mengambil satu halaman ke dest.
+// query sebaiknya sudah memakai Model(...) agar Count tahu tabelnya.
+// contoh penggunaan:
+// page, err := pagination.Paginate(db.Model(&entities.User{}).Preload("Roles"), params, &users)
+func Paginate(query *gorm.DB, params Params, dest interface{}) (responses.Pagination, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return responses.Pagination{}, err
+	}
+
+	if err := query.Session(&gorm.Session{}).Scopes(Scope(params)).Find(dest).Error; err != nil {
+		return responses.Pagination{}, err
+	}
+
+	return responses.Pagination{
+		Page:  params.Page,
+		Limit: params.Limit,
+		Total: total,
+	}, nil
+}
+
+// CursorPaginate mengambil satu halaman dengan keyset pagination (WHERE column > cursor ORDER BY column)
+// tanpa COUNT(*), cocok untuk tabel besar. key mengembalikan nilai column dari sebuah row
+// yang dipakai untuk membentuk next_cursor.
+// contoh penggunaan:
+// users, page, err := pagination.CursorPaginate(db.Model(&entities.User{}), params, "id", func(u entities.User) interface{} { return u.ID })
+func CursorPaginate[T any](query *gorm.DB, params Params, column string, key func(T) interface{}) ([]T, responses.Pagination, error) {
+	q := query.Session(&gorm.Session{})
+
+	if params.Cursor != "" {
+		after, err := DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, responses.Pagination{}, err
+		}
+		q = q.Where(fmt.Sprintf("%s > ?", column), after)
+	}
+
+	var rows []T
+	if err := q.Order(column).Limit(params.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, responses.Pagination{}, err
+	}
+
+	page := responses.Pagination{
+		Limit:   params.Limit,
+		HasPrev: params.Cursor != "",
+	}
+
+	// Ambil satu row lebih untuk tahu apakah masih ada halaman berikutnya
+	if len(rows) > params.Limit {
+		rows = rows[:params.Limit]
+		next, err := EncodeCursor(key(rows[len(rows)-1]))
+		if err != nil {
+			return nil, responses.Pagination{}, err
+		}
+		page.HasNext = true
+		page.NextCursor = next
+	}
+
+	return rows, page, nil
+}
+
+// EncodeCursor mengubah nilai key menjadi cursor opaque (base64url dari JSON)
+func EncodeCursor(value interface{}) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor kebalikan dari EncodeCursor
+func DecodeCursor(cursor string) (interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return value, nil
+}
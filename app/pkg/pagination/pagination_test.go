@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFromQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		query string
+		want  Params
+	}{
+		{"", Params{Page: 1, Limit: DefaultLimit, Mode: ModeOffset}},
+		{"page=3&limit=20", Params{Page: 3, Limit: 20, Mode: ModeOffset}},
+		{"page=-1&limit=abc", Params{Page: 1, Limit: DefaultLimit, Mode: ModeOffset}},
+		{"limit=1000", Params{Page: 1, Limit: MaxLimit, Mode: ModeOffset}},
+		{"mode=cursor&limit=5", Params{Page: 1, Limit: 5, Mode: ModeCursor}},
+		{"cursor=", Params{Page: 1, Limit: DefaultLimit, Mode: ModeCursor}},
+		{"cursor=abc", Params{Page: 1, Limit: DefaultLimit, Cursor: "abc", Mode: ModeCursor}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/users?"+tc.query, nil)
+
+			got := FromQuery(c)
+			if got != tc.want {
+				t.Errorf("FromQuery(%q) = %+v, want %+v", tc.query, got, tc.want)
+			}
+			if got.IsCursor() != (tc.want.Mode == ModeCursor) {
+				t.Errorf("IsCursor() = %v", got.IsCursor())
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor, err := EncodeCursor(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.(interface{ String() string }).String() != "42" {
+		t.Errorf("DecodeCursor = %v, want 42", value)
+	}
+
+	if _, err := DecodeCursor("%%%"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("DecodeCursor(garbage) error = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -12,7 +12,7 @@ func (s *Spatie) Middleware(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "Unauthenticated", nil, "[Permission Middleware]")
+			response.Unauthorized(c, "Unauthenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[Permission Middleware]")
 			c.Abort()
 			return
 		}
@@ -24,9 +24,9 @@ func (s *Spatie) Middleware(permission string) gin.HandlerFunc {
 			return
 		}
 
-		hasPermission, err := s.CheckPermission(u.ID, permission)
+		hasPermission, err := s.WithContext(c.Request.Context()).CheckPermission(u.ID, permission)
 		if err != nil || !hasPermission {
-			response.Forbidden(c, "Permission denied", err, "[Permission Middleware]")
+			response.Forbidden(c, "Permission denied", response.WithCode(response.CodeAuthPermissionRequired, err), "[Permission Middleware]")
 			c.Abort()
 			return
 		}
@@ -40,7 +40,7 @@ func (s *Spatie) RoleMiddleware(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "Unauthenticated", nil, "[Role Middleware]")
+			response.Unauthorized(c, "Unauthenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[Role Middleware]")
 			c.Abort()
 			return
 		}
@@ -52,7 +52,7 @@ func (s *Spatie) RoleMiddleware(roles ...string) gin.HandlerFunc {
 			return
 		}
 
-		userRoles, err := s.GetUserRoles(u.ID)
+		userRoles, err := s.WithContext(c.Request.Context()).GetUserRoles(u.ID)
 		if err != nil {
 			response.Error(c, 500, "Failed to check roles", err, "[Role Middleware]")
 			c.Abort()
@@ -73,7 +73,7 @@ func (s *Spatie) RoleMiddleware(roles ...string) gin.HandlerFunc {
 		}
 
 		if !hasRole {
-			response.Forbidden(c, "Role access denied", nil, "[Role Middleware]")
+			response.Forbidden(c, "Role access denied", response.WithCode(response.CodeAuthRoleRequired, nil), "[Role Middleware]")
 			c.Abort()
 			return
 		}
@@ -87,7 +87,7 @@ func (s *Spatie) AnyPermissionMiddleware(permissions ...string) gin.HandlerFunc
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "Unauthenticated", nil, "[AnyPermission Middleware]")
+			response.Unauthorized(c, "Unauthenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[AnyPermission Middleware]")
 			c.Abort()
 			return
 		}
@@ -100,14 +100,14 @@ func (s *Spatie) AnyPermissionMiddleware(permissions ...string) gin.HandlerFunc
 		}
 
 		for _, perm := range permissions {
-			hasPermission, err := s.CheckPermission(u.ID, perm)
+			hasPermission, err := s.WithContext(c.Request.Context()).CheckPermission(u.ID, perm)
 			if err == nil && hasPermission {
 				c.Next()
 				return
 			}
 		}
 
-		response.Forbidden(c, "Permission denied", nil, "[AnyPermission Middleware]")
+		response.Forbidden(c, "Permission denied", response.WithCode(response.CodeAuthPermissionRequired, nil), "[AnyPermission Middleware]")
 		c.Abort()
 	}
 }
@@ -117,7 +117,7 @@ func (s *Spatie) AllPermissionsMiddleware(permissions ...string) gin.HandlerFunc
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			response.Unauthorized(c, "Unauthenticated", nil, "[AllPermissions Middleware]")
+			response.Unauthorized(c, "Unauthenticated", response.WithCode(response.CodeAuthUnauthenticated, nil), "[AllPermissions Middleware]")
 			c.Abort()
 			return
 		}
@@ -130,10 +130,10 @@ func (s *Spatie) AllPermissionsMiddleware(permissions ...string) gin.HandlerFunc
 		}
 
 		for _, perm := range permissions {
-			hasPermission, err := s.CheckPermission(u.ID, perm)
+			hasPermission, err := s.WithContext(c.Request.Context()).CheckPermission(u.ID, perm)
 			if err != nil || !hasPermission {
 				mssg := "Permission denied: " + perm
-				response.Forbidden(c, mssg, err, "[AllPermissions Middleware]")
+				response.Forbidden(c, mssg, response.WithCode(response.CodeAuthPermissionRequired, err), "[AllPermissions Middleware]")
 				c.Abort()
 				return
 			}
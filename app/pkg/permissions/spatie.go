@@ -16,8 +16,9 @@ import (
 type Spatie struct {
 	db          *gorm.DB
 	repo        *Repository
+	ctx         context.Context
 	cache       map[string]interface{}
-	cacheMutex  sync.RWMutex
+	cacheMutex  *sync.RWMutex
 	cacheExpiry time.Duration
 }
 
@@ -26,10 +27,30 @@ func NewSpatie(db *gorm.DB) *Spatie {
 		db:          db,
 		repo:        NewRepository(db),
 		cache:       make(map[string]interface{}),
+		cacheMutex:  &sync.RWMutex{},
 		cacheExpiry: 5 * time.Minute,
 	}
 }
 
+// WithContext mengembalikan Spatie yang menjalankan semua query dengan ctx (biasanya
+// c.Request.Context()), sehingga query ikut batal saat request timeout / client disconnect.
+// Cache dipakai bersama dengan instance asal.
+// contoh penggunaan:
+// roles, err := spatie.WithContext(c.Request.Context()).GetUserRoles(user.ID)
+func (s *Spatie) WithContext(ctx context.Context) *Spatie {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// queryContext mengembalikan context query, context.Background() jika belum di-set lewat WithContext
+func (s *Spatie) queryContext() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
 // ==================== Role Management ====================
 
 func (s *Spatie) CreateRole(name, guardName string) (*entities.Roles, error) {
@@ -42,7 +63,7 @@ func (s *Spatie) CreateRole(name, guardName string) (*entities.Roles, error) {
 		GuardName: guardName,
 	}
 
-	if err := s.repo.CreateRole(context.Background(), role); err != nil {
+	if err := s.repo.CreateRole(s.queryContext(), role); err != nil {
 		return nil, err
 	}
 
@@ -50,20 +71,20 @@ func (s *Spatie) CreateRole(name, guardName string) (*entities.Roles, error) {
 }
 
 func (s *Spatie) FindRole(id uint) (*entities.Roles, error) {
-	return s.repo.FindRoleByID(context.Background(), id)
+	return s.repo.FindRoleByID(s.queryContext(), id)
 }
 
 func (s *Spatie) FindRoleByName(name string) (*entities.Roles, error) {
-	return s.repo.FindRoleByName(context.Background(), name)
+	return s.repo.FindRoleByName(s.queryContext(), name)
 }
 
 func (s *Spatie) DeleteRole(id uint) error {
-	return s.repo.DeleteRole(context.Background(), id)
+	return s.repo.DeleteRole(s.queryContext(), id)
 }
 
 func (s *Spatie) GetAllRoles() ([]entities.Roles, error) {
 	var roles []entities.Roles
-	if err := s.db.Find(&roles).Error; err != nil {
+	if err := s.db.WithContext(s.queryContext()).Find(&roles).Error; err != nil {
 		return nil, err
 	}
 	return roles, nil
@@ -81,7 +102,7 @@ func (s *Spatie) CreatePermission(name, guardName string) (*entities.Permission,
 		GuardName: guardName,
 	}
 
-	if err := s.repo.CreatePermission(context.Background(), perm); err != nil {
+	if err := s.repo.CreatePermission(s.queryContext(), perm); err != nil {
 		return nil, err
 	}
 
@@ -89,20 +110,20 @@ func (s *Spatie) CreatePermission(name, guardName string) (*entities.Permission,
 }
 
 func (s *Spatie) FindPermission(id uint) (*entities.Permission, error) {
-	return s.repo.FindPermissionByID(context.Background(), id)
+	return s.repo.FindPermissionByID(s.queryContext(), id)
 }
 
 func (s *Spatie) FindPermissionByName(name string) (*entities.Permission, error) {
-	return s.repo.FindPermissionByName(context.Background(), name)
+	return s.repo.FindPermissionByName(s.queryContext(), name)
 }
 
 func (s *Spatie) DeletePermission(id uint) error {
-	return s.repo.DeletePermission(context.Background(), id)
+	return s.repo.DeletePermission(s.queryContext(), id)
 }
 
 func (s *Spatie) GetAllPermissions() ([]entities.Permission, error) {
 	var perms []entities.Permission
-	if err := s.db.Find(&perms).Error; err != nil {
+	if err := s.db.WithContext(s.queryContext()).Find(&perms).Error; err != nil {
 		return nil, err
 	}
 	return perms, nil
@@ -113,7 +134,7 @@ func (s *Spatie) CheckPermission(userID uint, permissionName string) (bool, erro
 		return false, errors.New("invalid user ID or permission name")
 	}
 
-	hasPermission, err := s.repo.CheckUserPermission(context.Background(), userID, permissionName)
+	hasPermission, err := s.repo.CheckUserPermission(s.queryContext(), userID, permissionName)
 	if err != nil {
 		return false, fmt.Errorf("error checking permission: %w", err)
 	}
@@ -129,15 +150,15 @@ func (s *Spatie) AssignRole(userID uint, roleName string) error {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	return s.repo.AssignRoleToUser(context.Background(), userID, role.ID)
+	return s.repo.AssignRoleToUser(s.queryContext(), userID, role.ID)
 }
 
 func (s *Spatie) AssignPermissionToRole(roleID, permissionID uint) error {
-	return s.repo.AssignPermissionToRole(context.Background(), roleID, permissionID)
+	return s.repo.AssignPermissionToRole(s.queryContext(), roleID, permissionID)
 }
 
 func (s *Spatie) AssignDirectPermissionToUser(userID, permissionID uint) error {
-	return s.repo.AssignDirectPermissionToUser(context.Background(), userID, permissionID)
+	return s.repo.AssignDirectPermissionToUser(s.queryContext(), userID, permissionID)
 }
 
 // ==================== Checking ====================
@@ -175,14 +196,14 @@ func (s *Spatie) HasAnyRole(userID uint, roleNames []string) (bool, error) {
 }
 
 func (s *Spatie) HasPermission(userID uint, permissionName string) (bool, error) {
-	return s.repo.CheckUserPermission(context.Background(), userID, permissionName)
+	return s.repo.CheckUserPermission(s.queryContext(), userID, permissionName)
 }
 
 // ==================== Utility ====================
 
 func (s *Spatie) GetUserRoles(userID uint) ([]entities.Roles, error) {
 	var user entities.User
-	if err := s.db.Preload("Roles").First(&user, userID).Error; err != nil {
+	if err := s.db.WithContext(s.queryContext()).Preload("Roles").First(&user, userID).Error; err != nil {
 		return nil, err
 	}
 	return user.Roles, nil
@@ -190,7 +211,7 @@ func (s *Spatie) GetUserRoles(userID uint) ([]entities.Roles, error) {
 
 func (s *Spatie) GetRolePermissions(roleID uint) ([]entities.Permission, error) {
 	var role entities.Roles
-	if err := s.db.Preload("Permissions").First(&role, roleID).Error; err != nil {
+	if err := s.db.WithContext(s.queryContext()).Preload("Permissions").First(&role, roleID).Error; err != nil {
 		return nil, err
 	}
 	return role.Permissions, nil
@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"response-std/app/models/entities"
+	"response-std/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Nama policy bawaan (lihat Init)
+const (
+	PolicyGlobal = "global" // semua request, per IP
+	PolicyLogin  = "login"  // POST /auth/login, per IP
+	PolicyUser   = "user"   // route protected, per user login
+)
+
+// KeyFunc menentukan identitas klien untuk counter
+type KeyFunc func(c *gin.Context) string
+
+// Policy adalah batas Limit request per Window untuk setiap key
+type Policy struct {
+	Name   string
+	Limit  int64
+	Window time.Duration
+	Key    KeyFunc
+}
+
+// Result adalah hasil pengecekan satu request terhadap policy
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	ResetAt   time.Time
+}
+
+// RetryAfter adalah sisa waktu sampai window berikutnya (minimal 1 detik)
+func (r Result) RetryAfter() time.Duration {
+	d := time.Until(r.ResetAt)
+	if d < time.Second {
+		return time.Second
+	}
+	return d
+}
+
+var (
+	store       Store = NewMemoryStore()
+	policies          = make(map[string]Policy)
+	policyMutex sync.RWMutex
+)
+
+// Init memilih store (RATE_LIMIT_STORE=memory|redis) dan mendaftarkan policy bawaan dari config
+func Init(cfg *config.Config) error {
+	if strings.ToLower(cfg.RateLimitStore) == "redis" {
+		SetStore(NewRedisStore(config.RedisClient(), "ratelimit:"))
+	}
+
+	defaults := []struct {
+		name string
+		rate string
+		def  string
+		key  KeyFunc
+	}{
+		{PolicyGlobal, cfg.RateLimitGlobal, "600/1m", ByIP},
+		{PolicyLogin, cfg.RateLimitLogin, "5/1m", ByIP},
+		{PolicyUser, cfg.RateLimitUser, "120/1m", ByUser},
+	}
+
+	for _, d := range defaults {
+		raw := d.rate
+		if raw == "" {
+			raw = d.def
+		}
+		limit, window, err := ParseRate(raw)
+		if err != nil {
+			return fmt.Errorf("rate limit policy %s: %w", d.name, err)
+		}
+		Register(Policy{Name: d.name, Limit: limit, Window: window, Key: d.key})
+	}
+
+	return nil
+}
+
+// SetStore mengganti store yang dipakai semua policy
+func SetStore(s Store) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+	store = s
+}
+
+// Register menambahkan (atau mengganti) policy
+func Register(p Policy) {
+	if p.Key == nil {
+		p.Key = ByIP
+	}
+
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+	policies[p.Name] = p
+}
+
+// Lookup mencari policy berdasarkan nama
+func Lookup(name string) (Policy, bool) {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+	p, ok := policies[name]
+	return p, ok
+}
+
+// Allow mencatat satu hit untuk klien request ini
+func (p Policy) Allow(c *gin.Context) (Result, error) {
+	policyMutex.RLock()
+	s := store
+	policyMutex.RUnlock()
+
+	count, resetAt, err := s.Increment(c.Request.Context(), p.Name+":"+p.Key(c), p.Window)
+	if err != nil {
+		return Result{Allowed: true, Limit: p.Limit, Remaining: p.Limit}, err
+	}
+
+	remaining := p.Limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   count <= p.Limit,
+		Limit:     p.Limit,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// ParseRate membaca format "<limit>/<window>", mis. "5/1m", "10/s", "1000/1h"
+func ParseRate(raw string) (int64, time.Duration, error) {
+	limitPart, windowPart, ok := strings.Cut(strings.TrimSpace(raw), "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate %q, expected <limit>/<window>", raw)
+	}
+
+	limit, err := strconv.ParseInt(strings.TrimSpace(limitPart), 10, 64)
+	if err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate limit %q", limitPart)
+	}
+
+	windowPart = strings.TrimSpace(windowPart)
+	if windowPart != "" && (windowPart[0] < '0' || windowPart[0] > '9') {
+		windowPart = "1" + windowPart // "s" -> "1s"
+	}
+	window, err := time.ParseDuration(windowPart)
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate window %q", windowPart)
+	}
+
+	return limit, window, nil
+}
+
+// ---------------------------
+// KEY FUNCS
+// ---------------------------
+
+// ByIP membatasi per IP klien
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser membatasi per user login (pasang setelah AuthMiddleware); fallback ke IP
+func ByUser(c *gin.Context) string {
+	if value, exists := c.Get("user"); exists {
+		if user, ok := value.(entities.User); ok {
+			return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+		}
+	}
+	return ByIP(c)
+}
+
+// ByToken membatasi per personal access token (pasang setelah AuthMiddleware); fallback ke IP
+func ByToken(c *gin.Context) string {
+	if value, exists := c.Get("token"); exists {
+		if token, ok := value.(entities.PersonalAccessTokens); ok {
+			return "token:" + strconv.FormatUint(uint64(token.ID), 10)
+		}
+	}
+	return ByIP(c)
+}
+
+// ByAPIKey membatasi per header X-API-Key (disimpan sebagai hash); fallback ke IP
+func ByAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "apikey:" + hex.EncodeToString(sum[:8])
+	}
+	return ByIP(c)
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseRate(t *testing.T) {
+	cases := []struct {
+		raw    string
+		limit  int64
+		window time.Duration
+		ok     bool
+	}{
+		{"5/1m", 5, time.Minute, true},
+		{"10/s", 10, time.Second, true},
+		{" 1000 / 1h ", 1000, time.Hour, true},
+		{"5", 0, 0, false},
+		{"0/1m", 0, 0, false},
+		{"5/forever", 0, 0, false},
+		{"5/-1m", 0, 0, false},
+	}
+
+	for _, tc := range cases {
+		limit, window, err := ParseRate(tc.raw)
+		if (err == nil) != tc.ok {
+			t.Errorf("ParseRate(%q) error = %v, want ok=%v", tc.raw, err, tc.ok)
+			continue
+		}
+		if tc.ok && (limit != tc.limit || window != tc.window) {
+			t.Errorf("ParseRate(%q) = %d/%v, want %d/%v", tc.raw, limit, window, tc.limit, tc.window)
+		}
+	}
+}
+
+func TestPolicyAllow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetStore(NewMemoryStore())
+
+	policy := Policy{Name: "test-allow", Limit: 2, Window: time.Minute, Key: ByIP}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	for i, want := range []bool{true, true, false} {
+		result, err := policy.Allow(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != want {
+			t.Errorf("hit %d: Allowed = %v, want %v", i+1, result.Allowed, want)
+		}
+	}
+}
+
+// X-Forwarded-For hanya dipakai jika request datang dari TRUSTED_PROXIES
+func TestByIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name    string
+		trusted []string
+		want    string
+	}{
+		{"no trusted proxies", nil, "ip:203.0.113.7"},
+		{"peer is trusted proxy", []string{"203.0.113.0/24"}, "ip:198.51.100.1"},
+		{"peer is not trusted", []string{"10.0.0.0/8"}, "ip:203.0.113.7"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			if err := r.SetTrustedProxies(tc.trusted); err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			r.GET("/", func(c *gin.Context) { got = ByIP(c) })
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "203.0.113.7:1234"
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tc.want {
+				t.Errorf("ByIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+}
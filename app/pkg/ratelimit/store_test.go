@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStore menjalankan skenario yang sama untuk setiap implementasi Store
+func testStore(t *testing.T, s Store, advance func(time.Duration)) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		key    string
+		hits   int
+		window time.Duration
+		want   int64
+	}{
+		{"first hit", "a", 1, time.Minute, 1},
+		{"counts up", "b", 5, time.Minute, 5},
+		{"keys are independent", "c", 2, time.Minute, 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var count int64
+			var resetAt time.Time
+			var err error
+			for i := 0; i < tc.hits; i++ {
+				count, resetAt, err = s.Increment(ctx, tc.key, tc.window)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if count != tc.want {
+				t.Errorf("count = %d, want %d", count, tc.want)
+			}
+			if until := time.Until(resetAt); until <= 0 || until > tc.window {
+				t.Errorf("resetAt in %v, want within (0, %v]", until, tc.window)
+			}
+		})
+	}
+
+	t.Run("window expiry resets counter", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if _, _, err := s.Increment(ctx, "expiring", 50*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+		}
+		advance(60 * time.Millisecond)
+
+		count, _, err := s.Increment(ctx, "expiring", 50*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("count after window = %d, want 1", count)
+		}
+	})
+
+	t.Run("concurrent increments are not lost", func(t *testing.T) {
+		const workers = 50
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := s.Increment(ctx, "parallel", time.Minute); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		count, _, err := s.Increment(ctx, "parallel", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != workers+1 {
+			t.Errorf("count = %d, want %d", count, workers+1)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore(), time.Sleep)
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// miniredis tidak menjalankan TTL sendiri; FastForward memajukan waktunya
+	testStore(t, NewRedisStore(client, "test:"), mr.FastForward)
+
+	if !mr.Exists("test:a") {
+		t.Error("expected prefixed key test:a in redis")
+	}
+}
@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Spec mendeklarasikan apa saja yang boleh diminta klien untuk satu resource
+type Spec struct {
+	Fields   []string          // field yang boleh dipilih lewat ?fields=
+	Includes map[string]string // nama include di query -> path Preload GORM, mis. "roles.permissions": "Roles.Permissions"
+}
+
+// Options adalah hasil parsing ?fields= dan ?include=
+type Options struct {
+	Fields   []string
+	Includes []string
+	preloads []string
+}
+
+// FromQuery membaca ?fields=id,name&include=roles,roles.permissions dan memvalidasinya terhadap spec.
+// Error dikembalikan dalam bentuk {param: [pesan]} untuk response.ValidationError; nil jika valid.
+func FromQuery(c *gin.Context, spec Spec) (Options, map[string]interface{}) {
+	var opts Options
+	errs := make(map[string]interface{})
+
+	allowedFields := make(map[string]bool, len(spec.Fields))
+	for _, f := range spec.Fields {
+		allowedFields[f] = true
+	}
+
+	var fieldErrs []string
+	for _, f := range splitList(c.Query("fields")) {
+		if !allowedFields[f] {
+			fieldErrs = append(fieldErrs, fmt.Sprintf("Unknown field: %s", f))
+			continue
+		}
+		opts.Fields = append(opts.Fields, f)
+	}
+	if len(fieldErrs) > 0 {
+		errs["fields"] = fieldErrs
+	}
+
+	var includeErrs []string
+	for _, inc := range splitList(c.Query("include")) {
+		path, ok := spec.Includes[inc]
+		if !ok {
+			includeErrs = append(includeErrs, fmt.Sprintf("Unknown include: %s", inc))
+			continue
+		}
+		opts.Includes = append(opts.Includes, inc)
+		opts.preloads = append(opts.preloads, path)
+	}
+	if len(includeErrs) > 0 {
+		errs["include"] = includeErrs
+	}
+
+	if len(errs) > 0 {
+		return Options{}, errs
+	}
+	return opts, nil
+}
+
+// Preload hanya mem-preload relasi yang diminta lewat ?include=
+// contoh penggunaan:
+// db.Scopes(opts.Preload).First(&user, id)
+func (o Options) Preload(db *gorm.DB) *gorm.DB {
+	for _, path := range o.preloads {
+		db = db.Preload(path)
+	}
+	return db
+}
+
+// Has true jika relasi name (atau turunannya, mis. "roles.permissions" untuk "roles") diminta
+func (o Options) Has(name string) bool {
+	for _, inc := range o.Includes {
+		if inc == name || strings.HasPrefix(inc, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Sparse mengubah resource menjadi map yang hanya berisi field yang diminta.
+// Tanpa ?fields= semua field dikembalikan; relasi hanya muncul jika di-include.
+func Sparse(v interface{}, opts Options) map[string]interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var full map[string]interface{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil
+	}
+
+	if len(opts.Fields) == 0 {
+		return full
+	}
+
+	sparse := make(map[string]interface{}, len(opts.Fields)+len(opts.Includes))
+	for _, f := range opts.Fields {
+		if value, ok := full[f]; ok {
+			sparse[f] = value
+		}
+	}
+
+	// Relasi yang di-include selalu ikut walaupun tidak disebut di ?fields=
+	for _, inc := range opts.Includes {
+		root := strings.SplitN(inc, ".", 2)[0]
+		if value, ok := full[root]; ok {
+			sparse[root] = value
+		}
+	}
+
+	return sparse
+}
+
+// SparseEach menerapkan Sparse ke setiap item list
+func SparseEach[T any](items []T, opts Options) []map[string]interface{} {
+	list := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		list[i] = Sparse(item, opts)
+	}
+	return list
+}
+
+func splitList(raw string) []string {
+	var list []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
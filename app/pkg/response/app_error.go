@@ -0,0 +1,198 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// AppError adalah error domain yang membawa semua info untuk dirender sebagai respons:
+// status HTTP, error code, message untuk klien, level log, dan detail validasi (opsional).
+type AppError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Level   string
+	Fields  map[string]interface{}
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AppError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// NewError membuat AppError dengan status, code dan message eksplisit
+// contoh penggunaan:
+// return response.NewError(404, response.CodeUserNotFound, "User not found", err)
+func NewError(status int, code ErrorCode, message string, err error) *AppError {
+	return &AppError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Level:   levelForStatus(status),
+		Err:     err,
+	}
+}
+
+// ErrorFromCode membuat AppError dari entri katalog error code
+func ErrorFromCode(code ErrorCode, err error) *AppError {
+	info, ok := LookupErrorCode(code)
+	if !ok {
+		return NewError(500, code, "Internal server error occurred", err)
+	}
+	return NewError(info.Status, code, info.Message, err)
+}
+
+// ValidationError membuat AppError 422 dengan bentuk yang sama seperti UnprocessableValidation
+func ValidationError(message string, fields map[string]interface{}) *AppError {
+	return &AppError{
+		Status:  422,
+		Code:    CodeValidationFailed,
+		Message: message,
+		Level:   "warn",
+		Fields:  fields,
+	}
+}
+
+// PermissionDenied membuat AppError 403 untuk user yang tidak punya akses
+func PermissionDenied(message string, err error) *AppError {
+	return NewError(403, CodeAuthPermissionRequired, message, err)
+}
+
+// ToAppError memetakan error apapun ke AppError dengan memeriksa rantai errors.Is/As:
+// AppError apa adanya, record not found -> 404, duplicate key -> 409,
+// error validasi binding -> 422, body rusak -> 400, body terlalu besar -> 413, context deadline -> 504, sisanya -> 500.
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		resolved := *appErr
+		if resolved.Status == 0 {
+			resolved.Status = 500
+		}
+		if resolved.Level == "" {
+			resolved.Level = levelForStatus(resolved.Status)
+		}
+		if resolved.Message == "" {
+			resolved.Message = http.StatusText(resolved.Status)
+		}
+		return &resolved
+	}
+
+	var mysqlErr *mysql.MySQLError
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NewError(404, CodeResourceNotFound, "Resource not found", err)
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		errors.As(err, &mysqlErr) && mysqlErr.Number == 1062:
+		return NewError(409, CodeResourceConflict, "Resource already exists", err)
+	case errors.As(err, &validationErrs):
+		appErr := ValidationError("Validation failed", validationFields(validationErrs))
+		appErr.Err = err
+		return appErr
+	case errors.As(err, &maxBytesErr):
+		return NewError(413, CodeRequestTooLarge, "Request body too large", err)
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.Is(err, io.EOF):
+		return NewError(400, CodeRequestMalformed, "Invalid request format", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewError(504, CodeHandlerTimeout, "Request timed out", err)
+	default:
+		return NewError(500, CodeInternalError, "Internal server error occurred", err)
+	}
+}
+
+// FromError merespons berdasarkan error: status, message, level log dan error code
+// dipilih oleh ToAppError.
+func FromError(c *gin.Context, err error, logPrefix ...string) {
+	appErr := ToAppError(err)
+	prefix := getLogPrefix(logPrefix, "[FromError]")
+
+	if appErr.Fields != nil {
+		UnprocessableValidation(c, appErr.Message, appErr, appErr.Fields, prefix)
+		return
+	}
+
+	Error(c, appErr.Status, appErr.Message, appErr, prefix, appErr.Level)
+}
+
+// Handle mengubah handler yang mengembalikan error menjadi gin.HandlerFunc.
+// Error dicatat ke c.Errors lalu dirender oleh middleware.ErrorResponseMiddleware.
+// contoh penggunaan:
+// user.GET("/:id", response.Handle(userController.GetUserByID))
+func Handle(fn func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := fn(c); err != nil {
+			c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+func levelForStatus(status int) string {
+	if status >= 500 {
+		return "critical"
+	}
+	return "warn"
+}
+
+func init() {
+	// Nama field di error validasi mengikuti tag json (atau form untuk query), sama dengan payload klien
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName mengambil nama field dari tag json, lalu form; "-" berarti field diabaikan
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// validationFields mengubah error validator (tag binding) ke bentuk {field: [pesan]}
+func validationFields(errs validator.ValidationErrors) map[string]interface{} {
+	fields := make(map[string]interface{}, len(errs))
+	for _, fe := range errs {
+		name := fe.Field()
+		msg := fe.Field() + " failed on the '" + fe.Tag() + "' rule"
+
+		if existing, ok := fields[name].([]string); ok {
+			fields[name] = append(existing, msg)
+		} else {
+			fields[name] = []string{msg}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,57 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+func TestValidationFieldsUseJSONNames(t *testing.T) {
+	var input struct {
+		DisplayName string `json:"display_name" binding:"required"`
+		Page        int    `form:"page" binding:"min=1"`
+	}
+
+	err := binding.Validator.ValidateStruct(&input)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	appErr := ToAppError(err)
+	if appErr.Status != 422 || appErr.Code != CodeValidationFailed {
+		t.Fatalf("got %d %s, want 422 %s", appErr.Status, appErr.Code, CodeValidationFailed)
+	}
+	for _, field := range []string{"display_name", "page"} {
+		if _, ok := appErr.Fields[field]; !ok {
+			t.Errorf("missing field %q in %v", field, appErr.Fields)
+		}
+	}
+}
+
+func TestToAppError(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		{"app error", NewError(403, CodeAuthRoleRequired, "nope", nil), 403, CodeAuthRoleRequired},
+		{"not found", fmt.Errorf("find: %w", gorm.ErrRecordNotFound), 404, CodeResourceNotFound},
+		{"duplicate", gorm.ErrDuplicatedKey, 409, CodeResourceConflict},
+		{"deadline", context.DeadlineExceeded, 504, CodeHandlerTimeout},
+		{"unknown", errors.New("boom"), 500, CodeInternalError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			appErr := ToAppError(tc.err)
+			if appErr.Status != tc.status || appErr.Code != tc.code {
+				t.Errorf("got %d %s, want %d %s", appErr.Status, appErr.Code, tc.status, tc.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package response
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrorCode adalah kode error aplikasi yang stabil dan machine-readable.
+// Klien sebaiknya branching berdasarkan error_code, bukan isi message.
+type ErrorCode string
+
+// ErrorCodeInfo adalah entri katalog untuk satu ErrorCode
+type ErrorCodeInfo struct {
+	Code        ErrorCode `json:"code"`
+	Status      int       `json:"status"`
+	Message     string    `json:"message"`
+	Description string    `json:"description"`
+}
+
+// Auth
+const (
+	CodeAuthUnauthenticated     ErrorCode = "AUTH_UNAUTHENTICATED"
+	CodeAuthInvalidCredentials  ErrorCode = "AUTH_INVALID_CREDENTIALS"
+	CodeAuthTokenMissing        ErrorCode = "AUTH_TOKEN_MISSING"
+	CodeAuthTokenInvalid        ErrorCode = "AUTH_TOKEN_INVALID"
+	CodeAuthTokenExpired        ErrorCode = "AUTH_TOKEN_EXPIRED"
+	CodeAuthRoleRequired        ErrorCode = "AUTH_ROLE_REQUIRED"
+	CodeAuthPermissionRequired  ErrorCode = "AUTH_PERMISSION_REQUIRED"
+	CodeAuthAbilityRequired     ErrorCode = "AUTH_ABILITY_REQUIRED"
+	CodeAuthRegistrationFailure ErrorCode = "AUTH_REGISTRATION_FAILED"
+	CodeAuthAccountLocked       ErrorCode = "AUTH_ACCOUNT_LOCKED"
+	CodeAuthTooManyAttempts     ErrorCode = "AUTH_TOO_MANY_ATTEMPTS"
+	CodeAuthLoginUnavailable    ErrorCode = "AUTH_LOGIN_UNAVAILABLE"
+	CodeAuthRefreshTokenInvalid ErrorCode = "AUTH_REFRESH_TOKEN_INVALID"
+	CodeAuthRefreshTokenExpired ErrorCode = "AUTH_REFRESH_TOKEN_EXPIRED"
+	CodeAuthRefreshTokenReused  ErrorCode = "AUTH_REFRESH_TOKEN_REUSED"
+)
+
+// User
+const (
+	CodeUserNotFound   ErrorCode = "USER_NOT_FOUND"
+	CodeUserEmailTaken ErrorCode = "USER_EMAIL_TAKEN"
+)
+
+// Request & umum
+const (
+	CodeRequestMalformed ErrorCode = "REQUEST_MALFORMED"
+	CodeRequestTooLarge  ErrorCode = "REQUEST_TOO_LARGE"
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	CodeResourceNotFound ErrorCode = "RESOURCE_NOT_FOUND"
+	CodeResourceConflict ErrorCode = "RESOURCE_CONFLICT"
+	CodeResourceModified ErrorCode = "RESOURCE_MODIFIED"
+	CodeRateLimited      ErrorCode = "RATE_LIMITED"
+	CodeRequestTimeout   ErrorCode = "REQUEST_TIMEOUT"
+	CodeHandlerTimeout   ErrorCode = "HANDLER_TIMEOUT"
+	CodeNotAcceptable    ErrorCode = "NOT_ACCEPTABLE"
+	CodeInternalError    ErrorCode = "INTERNAL_ERROR"
+)
+
+// Idempotency
+const (
+	CodeIdempotencyKeyReused  ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress ErrorCode = "IDEMPOTENCY_IN_PROGRESS"
+)
+
+var (
+	errorCodes      = make(map[ErrorCode]ErrorCodeInfo)
+	errorCodesMutex sync.RWMutex
+)
+
+func init() {
+	RegisterErrorCode(CodeAuthUnauthenticated, 401, "Unauthenticated", "Request membutuhkan user yang sudah login.")
+	RegisterErrorCode(CodeAuthInvalidCredentials, 422, "Invalid credentials", "Username/email atau password salah.")
+	RegisterErrorCode(CodeAuthTokenMissing, 401, "Authorization header required", "Header Authorization Bearer tidak dikirim.")
+	RegisterErrorCode(CodeAuthTokenInvalid, 401, "Invalid or expired token", "Format token salah atau token tidak dikenali.")
+	RegisterErrorCode(CodeAuthTokenExpired, 401, "Token has expired", "Token sudah melewati expires_at, login ulang.")
+	RegisterErrorCode(CodeAuthRoleRequired, 403, "Role access denied", "User tidak memiliki role yang dibutuhkan.")
+	RegisterErrorCode(CodeAuthPermissionRequired, 403, "Permission denied", "User tidak memiliki permission yang dibutuhkan.")
+	RegisterErrorCode(CodeAuthAbilityRequired, 403, "Token ability denied", "Token tidak memiliki ability (scope) yang dibutuhkan, minta token baru dengan ability tersebut.")
+	RegisterErrorCode(CodeAuthRegistrationFailure, 422, "Registration failed", "Akun gagal dibuat.")
+	RegisterErrorCode(CodeAuthAccountLocked, 423, "Account temporarily locked", "Terlalu banyak login gagal, login dikunci sementara (lihat Retry-After).")
+	RegisterErrorCode(CodeAuthTooManyAttempts, 429, "Too many login attempts", "Login gagal beruntun, tunggu sesuai Retry-After sebelum mencoba lagi.")
+	RegisterErrorCode(CodeAuthLoginUnavailable, 503, "Login temporarily unavailable", "Store proteksi brute-force tidak bisa diakses, login ditolak sementara (lihat Retry-After).")
+	RegisterErrorCode(CodeAuthRefreshTokenInvalid, 401, "Invalid refresh token", "Refresh token tidak dikenali atau sudah dicabut, login ulang.")
+	RegisterErrorCode(CodeAuthRefreshTokenExpired, 401, "Refresh token has expired", "Refresh token melewati REFRESH_TOKEN_TTL, login ulang.")
+	RegisterErrorCode(CodeAuthRefreshTokenReused, 401, "Refresh token reuse detected", "Refresh token yang sudah dipakai dikirim ulang; semua token di sesi (family) tersebut dicabut, login ulang.")
+
+	RegisterErrorCode(CodeUserNotFound, 404, "User not found", "User dengan ID tersebut tidak ada.")
+	RegisterErrorCode(CodeUserEmailTaken, 422, "Email already in use", "Email sudah dipakai user lain.")
+
+	RegisterErrorCode(CodeRequestMalformed, 400, "Invalid request format", "Body request tidak bisa di-parse.")
+	RegisterErrorCode(CodeRequestTooLarge, 413, "Request body too large", "Body request melebihi BODY_LIMIT untuk route ini.")
+	RegisterErrorCode(CodeValidationFailed, 422, "Validation failed", "Satu atau lebih field tidak valid, detail ada di error.")
+	RegisterErrorCode(CodeResourceNotFound, 404, "Resource not found", "Data yang diminta tidak ditemukan.")
+	RegisterErrorCode(CodeResourceConflict, 409, "Resource already exists", "Data bentrok dengan unique key yang sudah ada.")
+	RegisterErrorCode(CodeResourceModified, 412, "Resource has been modified", "ETag di If-Match tidak cocok: resource sudah diubah, ambil ulang lalu kirim ulang perubahan.")
+	RegisterErrorCode(CodeRateLimited, 429, "Rate limit exceeded", "Terlalu banyak request, coba lagi nanti.")
+	RegisterErrorCode(CodeRequestTimeout, 408, "Request timeout", "Klien tidak selesai mengirim request dalam batas waktu (response.RequestTimeout).")
+	RegisterErrorCode(CodeHandlerTimeout, 504, "Request timed out", "Handler melewati batas waktu server (HANDLER_TIMEOUT), query dibatalkan.")
+	RegisterErrorCode(CodeNotAcceptable, 406, "Not Acceptable", "Header Accept / ?_format= tidak cocok dengan format respons yang terdaftar (json, xml, msgpack, csv).")
+	RegisterErrorCode(CodeIdempotencyKeyReused, 422, "Idempotency-Key already used with a different payload", "Idempotency-Key yang sama dikirim dengan body/URL berbeda, gunakan key baru.")
+	RegisterErrorCode(CodeIdempotencyInProgress, 409, "A request with this Idempotency-Key is still in progress", "Request pertama dengan key ini belum selesai, retry setelah Retry-After.")
+	RegisterErrorCode(CodeInternalError, 500, "Internal server error occurred", "Kesalahan tak terduga di server.")
+}
+
+// RegisterErrorCode menambahkan (atau menimpa) entri di katalog error code
+func RegisterErrorCode(code ErrorCode, status int, message, description string) {
+	errorCodesMutex.Lock()
+	defer errorCodesMutex.Unlock()
+
+	errorCodes[code] = ErrorCodeInfo{
+		Code:        code,
+		Status:      status,
+		Message:     message,
+		Description: description,
+	}
+}
+
+// LookupErrorCode mencari entri katalog untuk code
+func LookupErrorCode(code ErrorCode) (ErrorCodeInfo, bool) {
+	errorCodesMutex.RLock()
+	defer errorCodesMutex.RUnlock()
+
+	info, ok := errorCodes[code]
+	return info, ok
+}
+
+// ErrorCodes mengembalikan seluruh katalog, diurutkan berdasarkan code
+func ErrorCodes() []ErrorCodeInfo {
+	errorCodesMutex.RLock()
+	defer errorCodesMutex.RUnlock()
+
+	list := make([]ErrorCodeInfo, 0, len(errorCodes))
+	for _, info := range errorCodes {
+		list = append(list, info)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+
+	return list
+}
+
+// codedError menempelkan ErrorCode ke sebuah error
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return string(e.code)
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func (e *codedError) ErrorCode() ErrorCode {
+	return e.code
+}
+
+// WithCode menempelkan ErrorCode ke err sehingga helper response.* menulis error_code.
+// err boleh nil.
+// contoh penggunaan:
+// response.UnprocessableEntity(c, "Invalid credentials", response.WithCode(response.CodeAuthInvalidCredentials, err), "[Login]")
+func WithCode(code ErrorCode, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// errorCodeOf mencari ErrorCode di sepanjang rantai error
+func errorCodeOf(err error) ErrorCode {
+	var coder interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package response
+
+import (
+	"testing"
+
+	"response-std/app/pkg/i18n"
+)
+
+func TestErrorCodesAreTranslated(t *testing.T) {
+	for _, info := range ErrorCodes() {
+		for _, locale := range []string{"en", "id"} {
+			if !i18n.Has(locale, "errors."+string(info.Code)) {
+				t.Errorf("%s: missing %s translation", info.Code, locale)
+			}
+		}
+	}
+}
+
+func TestTimeoutCodesMatchHelpers(t *testing.T) {
+	cases := []struct {
+		code   ErrorCode
+		status int
+	}{
+		{CodeRequestTimeout, 408}, // response.RequestTimeout
+		{CodeHandlerTimeout, 504}, // response.GatewayTimeout (Timeout middleware)
+	}
+
+	for _, tc := range cases {
+		info, ok := LookupErrorCode(tc.code)
+		if !ok {
+			t.Fatalf("%s is not registered", tc.code)
+		}
+		if info.Status != tc.status {
+			t.Errorf("%s: status = %d, want %d", tc.code, info.Status, tc.status)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"response-std/app/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const etagKey = "response.etag"
+
+// varyHeaders adalah header request yang memengaruhi representasi (format & bahasa)
+var varyHeaders = []string{"Accept", "Accept-Language"}
+
+// ETag menghitung weak ETag dari payload (hash JSON). Dipakai otomatis untuk GET/HEAD 200
+// oleh Success & Paginated jika handler tidak memanggil SetETag.
+func ETag(payload any) string {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	return weakETag(body)
+}
+
+// VersionETag membuat weak ETag dari identitas & versi entity, biasanya ID + updated_at.
+// Cukup untuk If-None-Match; untuk If-Match pakai RepresentationETag (weak tidak pernah cocok).
+// contoh penggunaan:
+// response.SetETag(c, response.VersionETag("user", user.ID, user.UpdatedAt.Unix()))
+func VersionETag(parts ...any) string {
+	return weakETag(joinParts(parts))
+}
+
+// StrongETag membuat strong ETag dari identitas & versi entity saja, tanpa representasi.
+// Dipakai sebagai validator If-Match (lihat IfMatch), bukan untuk respons GET.
+// contoh penggunaan:
+// response.IfMatch(c, response.StrongETag("user", user.ID, user.Version))
+func StrongETag(parts ...any) string {
+	return `"` + hashParts(parts) + `"`
+}
+
+// RepresentationETag membuat strong ETag dari versi entity plus representasi: format hasil
+// negosiasi, locale, ?fields dan ?include, sehingga ?fields=name tidak berbagi ETag dengan
+// respons lengkap (If-None-Match / 304). Bentuknya "<versi>-<representasi>" dengan bagian versi
+// sama dengan StrongETag(parts...), sehingga IfMatch menerima ETag dari representasi mana pun.
+// contoh penggunaan:
+// response.SetETag(c, response.RepresentationETag(c, "user", user.ID, user.Version))
+func RepresentationETag(c *gin.Context, parts ...any) string {
+	representation := []any{NegotiatedFormat(c), i18n.Locale(c), c.Query("fields"), c.Query("include")}
+	return `"` + hashParts(parts) + "-" + hashParts(representation) + `"`
+}
+
+// SetETag memakai etag untuk respons ini (menggantikan ETag otomatis dari payload)
+func SetETag(c *gin.Context, etag string) {
+	c.Set(etagKey, etag)
+}
+
+// IfMatch memeriksa header If-Match terhadap validator resource saat ini (StrongETag) dengan
+// strong comparison (RFC 9110): weak ETag (W/"...") tidak pernah cocok. Hanya bagian versi yang
+// dibandingkan, jadi ETag dari GET ?fields=name, Accept: application/xml atau bahasa lain tetap
+// valid untuk update. Mengembalikan true jika header tidak dikirim, berisi "*", atau cocok; false
+// berarti resource sudah berubah dan handler harus menjawab 412 (lihat PreconditionFailed).
+func IfMatch(c *gin.Context, current string) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+
+	candidates := strings.Split(header, ",")
+	for i, candidate := range candidates {
+		candidates[i] = versionPart(strings.TrimSpace(candidate))
+	}
+	return etagMatches(strings.Join(candidates, ","), versionPart(current), true)
+}
+
+// versionPart membuang bagian representasi dari ETag RepresentationETag: "<versi>-<repr>" → "<versi>"
+func versionPart(etag string) string {
+	if i := strings.IndexByte(etag, '-'); i >= 0 && strings.HasSuffix(etag, `"`) {
+		return etag[:i] + `"`
+	}
+	return etag
+}
+
+// writeETag menulis header ETag dan menjawab 304 jika If-None-Match cocok (hanya GET/HEAD).
+// Mengembalikan true jika respons 304 sudah dikirim.
+func writeETag(c *gin.Context, statusCode int, payload any) bool {
+	if statusCode < 200 || statusCode > 299 {
+		return false
+	}
+
+	safe := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+	etag := c.GetString(etagKey)
+	if etag == "" && safe && statusCode == http.StatusOK && payload != nil {
+		// Payload JSON sama untuk semua format, jadi format ikut di-hash
+		if body, err := json.Marshal(payload); err == nil {
+			etag = weakETag(joinParts([]any{NegotiatedFormat(c), string(body)}))
+		}
+	}
+	if etag == "" {
+		return false
+	}
+
+	c.Header("ETag", etag)
+	addVary(c, varyHeaders...)
+	if safe && etagMatches(c.GetHeader("If-None-Match"), etag, false) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// etagMatches membandingkan daftar ETag di header dengan etag (RFC 9110). Strong comparison
+// (If-Match) mensyaratkan keduanya bukan weak; weak comparison (If-None-Match) mengabaikan W/.
+func etagMatches(header, etag string, strong bool) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return header == "*"
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary menambah header ke Vary tanpa menimpa nilai dari middleware lain (mis. Accept-Encoding)
+func addVary(c *gin.Context, names ...string) {
+	existing := make(map[string]bool)
+	for _, value := range c.Writer.Header().Values("Vary") {
+		for _, token := range strings.Split(value, ",") {
+			existing[strings.ToLower(strings.TrimSpace(token))] = true
+		}
+	}
+	for _, name := range names {
+		if !existing[strings.ToLower(name)] {
+			c.Writer.Header().Add("Vary", name)
+		}
+	}
+}
+
+// joinParts menggabungkan parts dengan pemisah agar ("ab", "c") dan ("a", "bc") berbeda
+func joinParts(parts []any) []byte {
+	var b strings.Builder
+	for _, part := range parts {
+		fmt.Fprint(&b, part)
+		b.WriteByte(0)
+	}
+	return []byte(b.String())
+}
+
+// hashParts adalah hash hex (128 bit) dari parts
+func hashParts(parts []any) string {
+	sum := sha256.Sum256(joinParts(parts))
+	return hex.EncodeToString(sum[:16])
+}
+
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
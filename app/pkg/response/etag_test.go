@@ -0,0 +1,160 @@
+package response
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRepresentationETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	etagFor := func(target, accept string) string {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", target, nil)
+		if accept != "" {
+			c.Request.Header.Set("Accept", accept)
+		}
+		return RepresentationETag(c, "user", 1, 7)
+	}
+
+	base := etagFor("/users/1", "")
+	if base != etagFor("/users/1", "application/json") {
+		t.Error("default format and explicit json should share an etag")
+	}
+
+	cases := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{"fields", "/users/1?fields=name", ""},
+		{"include", "/users/1?include=roles", ""},
+		{"format", "/users/1", "application/xml"},
+		{"format param", "/users/1?_format=csv", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if etagFor(tc.target, tc.accept) == base {
+				t.Errorf("%s should change the etag", tc.name)
+			}
+		})
+	}
+}
+
+func TestIfMatchComparesVersionOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ifMatch := func(header, current string) bool {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("PUT", "/users/1", nil)
+		c.Request.Header.Set("If-Match", header)
+		return IfMatch(c, current)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/users/1?fields=name", nil)
+	c.Request.Header.Set("Accept", "application/xml")
+	etag := RepresentationETag(c, "user", 1, 7)
+
+	if !ifMatch(etag, StrongETag("user", 1, 7)) {
+		t.Error("representation etag of the current version should match")
+	}
+	if !ifMatch(`"other", `+etag, StrongETag("user", 1, 7)) {
+		t.Error("etag in a list should match")
+	}
+	if ifMatch(etag, StrongETag("user", 1, 8)) {
+		t.Error("etag of an older version must not match")
+	}
+	if ifMatch("W/"+etag, StrongETag("user", 1, 7)) {
+		t.Error("weak etag must not match (strong comparison)")
+	}
+}
+
+func TestWriteETagSetsVary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users", nil)
+	c.Header("Vary", "Accept-Encoding")
+
+	writeETag(c, 200, gin.H{"id": 1})
+
+	if c.Writer.Header().Get("ETag") == "" {
+		t.Fatal("missing ETag")
+	}
+	tokens := make(map[string]int)
+	for _, value := range c.Writer.Header().Values("Vary") {
+		for _, token := range strings.Split(value, ",") {
+			tokens[strings.TrimSpace(token)]++
+		}
+	}
+	for _, want := range []string{"Accept-Encoding", "Accept", "Accept-Language"} {
+		if tokens[want] != 1 {
+			t.Errorf("Vary = %v, want %s exactly once", c.Writer.Header().Values("Vary"), want)
+		}
+	}
+
+	// ETag kedua (mis. 304 setelah SetETag) tidak menduplikasi Vary
+	addVary(c, varyHeaders...)
+	if n := len(c.Writer.Header().Values("Vary")); n != 3 {
+		t.Errorf("Vary values = %d, want 3", n)
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	payload := gin.H{"id": 1}
+
+	first := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(first)
+	c.Request = httptest.NewRequest("GET", "/users", nil)
+	writeETag(c, 200, payload)
+	etag := first.Header().Get("ETag")
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/users", nil)
+	c.Request.Header.Set("If-None-Match", etag)
+	if !writeETag(c, 200, payload) {
+		t.Error("matching If-None-Match should answer 304")
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/users", nil)
+	c.Request.Header.Set("Accept", "application/xml")
+	c.Request.Header.Set("If-None-Match", etag)
+	if writeETag(c, 200, payload) {
+		t.Error("json etag must not validate the xml representation")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		etag   string
+		strong bool
+		want   bool
+	}{
+		{"weak equal", `W/"a"`, `W/"a"`, false, true},
+		{"weak ignores W/ prefix", `"a"`, `W/"a"`, false, true},
+		{"weak list", `"x", W/"a"`, `"a"`, false, true},
+		{"weak mismatch", `"b"`, `"a"`, false, false},
+		{"strong equal", `"a"`, `"a"`, true, true},
+		{"strong list", `"x", "a"`, `"a"`, true, true},
+		{"strong rejects weak candidate", `W/"a"`, `"a"`, true, false},
+		{"strong rejects weak etag", `"a"`, `W/"a"`, true, false},
+		{"star", `*`, `"a"`, true, true},
+		{"empty header", ``, `"a"`, true, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := etagMatches(tc.header, tc.etag, tc.strong); got != tc.want {
+				t.Errorf("etagMatches(%q, %q, %v) = %v, want %v", tc.header, tc.etag, tc.strong, got, tc.want)
+			}
+		})
+	}
+}
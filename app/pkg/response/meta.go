@@ -0,0 +1,74 @@
+package response
+
+import (
+	"strings"
+	"time"
+
+	"response-std/app/pkg/i18n"
+	"response-std/libs/requestid"
+	"response-std/libs/responses"
+	"response-std/libs/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Meta adalah blok "meta" opsional di envelope
+type Meta = responses.Meta
+
+// MetaHeader bisa dikirim klien dengan nilai "false" untuk tidak menerima blok meta (sama dengan ?meta=false)
+const MetaHeader = "X-Response-Meta"
+
+const metaKey = "response.meta"
+
+type metaState struct {
+	start   time.Time
+	version string
+}
+
+// StartMeta mengaktifkan blok meta untuk request ini; dipanggil oleh middleware,
+// bukan controller. Waktu proses dihitung dari sini sampai respons dirender.
+func StartMeta(c *gin.Context, version string) {
+	c.Set(metaKey, metaState{start: time.Now(), version: version})
+}
+
+// metaOf mengembalikan nil jika meta tidak aktif atau klien opt-out
+func metaOf(c *gin.Context) *Meta {
+	value, ok := c.Get(metaKey)
+	if !ok {
+		return nil
+	}
+	state, ok := value.(metaState)
+	if !ok || metaOptedOut(c) {
+		return nil
+	}
+
+	meta := &Meta{
+		ProcessingTime: time.Since(state.start).Round(time.Microsecond).String(),
+		APIVersion:     state.version,
+		RequestID:      requestid.FromContext(c),
+	}
+
+	if d, deprecated := router.DeprecationOf(state.version); deprecated {
+		message := i18n.T(c, "meta.deprecated", state.version)
+		if d.Sunset != nil {
+			message = i18n.T(c, "meta.deprecated_sunset", state.version, d.Sunset.Format("2006-01-02"))
+		}
+		meta.Deprecation = &responses.DeprecationMeta{
+			Deprecated: true,
+			Sunset:     d.Sunset,
+			Message:    message,
+		}
+	}
+
+	return meta
+}
+
+func metaOptedOut(c *gin.Context) bool {
+	for _, v := range []string{c.Query("meta"), c.GetHeader(MetaHeader)} {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "false", "0", "off", "no":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package response
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"response-std/app/pkg/i18n"
+	"response-std/libs/requestid"
+	"response-std/libs/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Paginated merespons list data dengan bentuk responses.PaginatedResponse.
+// total_pages, has_next dan has_prev dihitung di sini untuk offset pagination;
+// untuk cursor pagination (Page == 0) nilai dari pagination.CursorPaginate dipakai apa adanya.
+// Header Link (first, prev, next, last) dan ETag ikut diset. message boleh berupa translation key.
+func Paginated(c *gin.Context, message string, data any, pagination responses.Pagination) {
+	if pagination.Page > 0 && pagination.Limit > 0 {
+		pagination.TotalPages = int((pagination.Total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+		pagination.HasNext = pagination.Page < pagination.TotalPages
+		pagination.HasPrev = pagination.Page > 1
+	}
+
+	if link := linkHeader(c, pagination); link != "" {
+		c.Header("Link", link)
+	}
+
+	if writeETag(c, 200, gin.H{"data": data, "pagination": pagination}) {
+		return
+	}
+
+	render(c, 200, responses.PaginatedResponse{
+		Success:    true,
+		Message:    i18n.T(c, message),
+		Data:       data,
+		Pagination: pagination,
+		RequestID:  requestid.FromContext(c),
+		Meta:       metaOf(c),
+		Timestamp:  time.Now(),
+	})
+}
+
+// linkHeader membangun header Link (RFC 8288) dari URL request saat ini
+func linkHeader(c *gin.Context, p responses.Pagination) string {
+	var links []string
+
+	add := func(rel string, params map[string]string) {
+		u := *c.Request.URL
+		q := u.Query()
+		for k, v := range params {
+			if v == "" {
+				q.Del(k)
+			} else {
+				q.Set(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+		links = append(links, "<"+u.RequestURI()+`>; rel="`+rel+`"`)
+	}
+
+	limit := strconv.Itoa(p.Limit)
+
+	// Cursor pagination hanya punya link next
+	if p.Page == 0 {
+		if p.HasNext {
+			add("next", map[string]string{"cursor": p.NextCursor, "limit": limit, "page": ""})
+		}
+		return strings.Join(links, ", ")
+	}
+
+	add("first", map[string]string{"page": "1", "limit": limit})
+	if p.HasPrev {
+		add("prev", map[string]string{"page": strconv.Itoa(p.Page - 1), "limit": limit})
+	}
+	if p.HasNext {
+		add("next", map[string]string{"page": strconv.Itoa(p.Page + 1), "limit": limit})
+	}
+	if p.TotalPages > 0 {
+		add("last", map[string]string{"page": strconv.Itoa(p.TotalPages), "limit": limit})
+	}
+
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,121 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"response-std/config"
+	"response-std/libs/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format body untuk respons error
+const (
+	ErrorFormatEnvelope = "envelope" // {status, code, message} (legacy)
+	ErrorFormatProblem  = "problem"  // RFC 7807 application/problem+json
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	errorFormatKey = "response.error_format"
+)
+
+// Problem represents an RFC 7807 problem details object
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON menggabungkan extension members dengan member standar RFC 7807.
+// Member standar selalu menang jika ada nama yang bentrok.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		body[k] = v
+	}
+
+	body["type"] = p.Type
+	body["title"] = p.Title
+	body["status"] = p.Status
+	if p.Detail != "" {
+		body["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		body["instance"] = p.Instance
+	}
+
+	return json.Marshal(body)
+}
+
+// SetErrorFormat memilih format error untuk request ini (dipakai oleh middleware per route group)
+func SetErrorFormat(c *gin.Context, format string) {
+	c.Set(errorFormatKey, strings.ToLower(format))
+}
+
+// wantsProblem menentukan apakah error harus dirender sebagai problem+json.
+// Urutan: header Accept -> format route group -> ERROR_FORMAT di config -> envelope.
+func wantsProblem(c *gin.Context) bool {
+	// application/problem+json atau application/problem+xml
+	if strings.Contains(c.GetHeader("Accept"), "application/problem+") {
+		return true
+	}
+
+	if format := c.GetString(errorFormatKey); format != "" {
+		return format == ErrorFormatProblem
+	}
+
+	if config.ENV != nil {
+		return strings.ToLower(config.ENV.ErrorFormat) == ErrorFormatProblem
+	}
+
+	return false
+}
+
+func newProblem(c *gin.Context, statusCode int, detail string, extensions map[string]interface{}) Problem {
+	if id := requestid.FromContext(c); id != "" {
+		if extensions == nil {
+			extensions = make(map[string]interface{}, 1)
+		}
+		extensions["request_id"] = id
+	}
+	if meta := metaOf(c); meta != nil {
+		if extensions == nil {
+			extensions = make(map[string]interface{}, 1)
+		}
+		extensions["meta"] = meta
+	}
+
+	return Problem{
+		Type:       problemType(statusCode),
+		Title:      http.StatusText(statusCode),
+		Status:     statusCode,
+		Detail:     detail,
+		Instance:   c.Request.URL.Path,
+		Extensions: extensions,
+	}
+}
+
+// problemType mengembalikan URI type; "about:blank" jika PROBLEM_TYPE_BASE_URL tidak diisi
+func problemType(statusCode int) string {
+	if config.ENV == nil || config.ENV.ProblemTypeBaseURL == "" {
+		return "about:blank"
+	}
+
+	slug := strings.ToLower(strings.ReplaceAll(http.StatusText(statusCode), " ", "-"))
+	if slug == "" {
+		slug = "error"
+	}
+
+	return strings.TrimRight(config.ENV.ProblemTypeBaseURL, "/") + "/" + slug
+}
+
+func problemRespond(c *gin.Context, problem Problem) {
+	render(c, problem.Status, problem)
+}
@@ -0,0 +1,480 @@
+package response
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"response-std/app/pkg/i18n"
+	"response-std/libs/requestid"
+
+	"github.com/gin-gonic/gin"
+	ginrender "github.com/gin-gonic/gin/render"
+)
+
+// Renderer menulis payload (Response, ErrorResponse, PaginatedResponse atau Problem) dalam satu format
+type Renderer interface {
+	Render(c *gin.Context, status int, payload any) error
+}
+
+// RendererFunc adalah adapter agar fungsi biasa bisa dipakai sebagai Renderer
+type RendererFunc func(c *gin.Context, status int, payload any) error
+
+func (f RendererFunc) Render(c *gin.Context, status int, payload any) error {
+	return f(c, status, payload)
+}
+
+type renderEntry struct {
+	name       string
+	mediaTypes []string
+	renderer   Renderer
+}
+
+var (
+	// renderer pertama adalah default untuk Accept kosong atau */*
+	renderers      []renderEntry
+	renderersMutex sync.RWMutex
+)
+
+func init() {
+	RegisterRenderer("json", []string{"application/json", "application/problem+json"}, RendererFunc(renderJSON))
+	RegisterRenderer("xml", []string{"application/xml", "text/xml", "application/problem+xml"}, RendererFunc(renderXML))
+	RegisterRenderer("msgpack", []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, RendererFunc(renderMsgPack))
+	RegisterRenderer("csv", []string{"text/csv"}, RendererFunc(renderCSV))
+}
+
+// FormatParams adalah query param untuk memaksa format respons, dicek berurutan. "_format" menang
+// atas "format" untuk endpoint yang memakai ?format= sebagai param sendiri.
+var FormatParams = []string{"_format", "format"}
+
+// RegisterRenderer menambahkan (atau mengganti) renderer untuk format name (dipakai di ?format=)
+// dan media type yang dicocokkan persis dengan header Accept
+func RegisterRenderer(name string, mediaTypes []string, r Renderer) {
+	renderersMutex.Lock()
+	defer renderersMutex.Unlock()
+
+	entry := renderEntry{name: name, mediaTypes: mediaTypes, renderer: r}
+	for i, existing := range renderers {
+		if existing.name == name {
+			renderers[i] = entry
+			return
+		}
+	}
+	renderers = append(renderers, entry)
+}
+
+// render adalah satu-satunya jalur penulisan body envelope.
+// Format dipilih dari ?_format= / ?format= lalu header Accept; 406 (envelope JSON) jika tidak ada yang cocok.
+func render(c *gin.Context, status int, payload any) {
+	c.Header("Content-Language", i18n.Locale(c))
+
+	entry, ok := negotiate(c)
+	if !ok {
+		c.JSON(http.StatusNotAcceptable, Response{
+			Status:    "error",
+			Code:      http.StatusNotAcceptable,
+			ErrorCode: CodeNotAcceptable,
+			Message:   i18n.T(c, "errors."+string(CodeNotAcceptable)),
+			RequestID: requestid.FromContext(c),
+			Meta:      metaOf(c),
+		})
+		return
+	}
+
+	if err := entry.renderer.Render(c, status, payload); err != nil {
+		if log != nil {
+			log.WithContext(c).Error("Failed to render response as "+entry.name, err, map[string]interface{}{
+				"request": c.Request.URL.Path,
+			})
+		}
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// NegotiatedFormat mengembalikan nama format yang akan dipakai untuk request ini (mis. "json"),
+// "json" juga untuk request yang akan dijawab 406
+func NegotiatedFormat(c *gin.Context) string {
+	if entry, ok := negotiate(c); ok {
+		return entry.name
+	}
+	return "json"
+}
+
+// negotiate memilih renderer. Hanya media type yang terdaftar yang dicocokkan (tidak ada pemetaan
+// suffix +json / +xml). Accept kosong, */* atau navigasi browser (text/html) memakai renderer
+// default (JSON); Accept atau format param yang tidak dikenal -> false (406).
+func negotiate(c *gin.Context) (renderEntry, bool) {
+	renderersMutex.RLock()
+	defer renderersMutex.RUnlock()
+
+	if len(renderers) == 0 {
+		return renderEntry{}, false
+	}
+
+	// Format param selalu menang atas header Accept
+	for _, param := range FormatParams {
+		format := strings.ToLower(strings.TrimSpace(c.Query(param)))
+		if format == "" {
+			continue
+		}
+		for _, entry := range renderers {
+			if entry.name == format {
+				return entry, true
+			}
+		}
+		return renderEntry{}, false
+	}
+
+	accept := c.GetHeader("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return renderers[0], true
+	}
+
+	for _, mediaType := range parseAccept(accept) {
+		// Navigasi browser (text/html,application/xhtml+xml,application/xml;q=0.9,...) tetap JSON
+		if mediaType == "text/html" || mediaType == "*/*" || mediaType == "application/*" {
+			return renderers[0], true
+		}
+
+		for _, entry := range renderers {
+			for _, mt := range entry.mediaTypes {
+				if mt == mediaType {
+					return entry, true
+				}
+			}
+		}
+	}
+
+	return renderEntry{}, false
+}
+
+// parseAccept mengembalikan media type dari header Accept, diurutkan berdasarkan q (tertinggi dulu)
+func parseAccept(header string) []string {
+	type mediaRange struct {
+		value string
+		q     float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > 0 {
+			ranges = append(ranges, mediaRange{value: value, q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	list := make([]string, len(ranges))
+	for i, r := range ranges {
+		list[i] = r.value
+	}
+	return list
+}
+
+// ---------------------------
+// JSON
+// ---------------------------
+func renderJSON(c *gin.Context, status int, payload any) error {
+	if problem, ok := payload.(Problem); ok {
+		body, err := json.Marshal(problem)
+		if err != nil {
+			return err
+		}
+		c.Data(status, ProblemContentType, body)
+		return nil
+	}
+
+	c.JSON(status, payload)
+	return nil
+}
+
+// ---------------------------
+// XML
+// ---------------------------
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func renderXML(c *gin.Context, status int, payload any) error {
+	value, err := toOrdered(payload)
+	if err != nil {
+		return err
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: "response"}}
+	contentType := "application/xml; charset=utf-8"
+	if _, ok := payload.(Problem); ok {
+		root = xml.StartElement{Name: xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}}
+		contentType = "application/problem+xml; charset=utf-8"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := writeXMLValue(enc, root, value); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	c.Data(status, contentType, buf.Bytes())
+	return nil
+}
+
+func writeXMLValue(enc *xml.Encoder, start xml.StartElement, value interface{}) error {
+	switch v := value.(type) {
+	case *orderedMap:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range v.keys {
+			if err := writeXMLValue(enc, xmlElement(key), v.values[key]); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case []interface{}:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := writeXMLValue(enc, xmlElement("item"), item); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case nil:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return enc.EncodeElement(fmt.Sprint(v), start)
+	}
+}
+
+// xmlElement memakai key sebagai nama element, atau <entry key="..."> jika key bukan nama XML yang valid
+func xmlElement(key string) xml.StartElement {
+	if xmlNamePattern.MatchString(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+// ---------------------------
+// MESSAGEPACK
+// ---------------------------
+func renderMsgPack(c *gin.Context, status int, payload any) error {
+	value, err := toOrdered(payload)
+	if err != nil {
+		return err
+	}
+
+	c.Render(status, ginrender.MsgPack{Data: toPlain(value)})
+	return nil
+}
+
+// ---------------------------
+// CSV
+// ---------------------------
+// renderCSV menulis isi "data" sebagai baris CSV (list -> banyak baris, object -> satu baris).
+// Respons tanpa data (mis. error) ditulis sebagai satu baris dari field envelope.
+func renderCSV(c *gin.Context, status int, payload any) error {
+	value, err := toOrdered(payload)
+	if err != nil {
+		return err
+	}
+
+	envelope, _ := value.(*orderedMap)
+	if envelope == nil {
+		return fmt.Errorf("csv renderer: unsupported payload %T", payload)
+	}
+
+	var rows []*orderedMap
+	switch data := envelope.values["data"].(type) {
+	case []interface{}:
+		for _, item := range data {
+			if row, ok := item.(*orderedMap); ok {
+				rows = append(rows, row)
+			} else {
+				rows = append(rows, &orderedMap{keys: []string{"value"}, values: map[string]interface{}{"value": item}})
+			}
+		}
+	case *orderedMap:
+		rows = append(rows, data)
+	default:
+		rows = append(rows, envelope)
+	}
+
+	// Kolom adalah gabungan key dari semua baris sesuai urutan kemunculan
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, key := range row.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = csvCell(row.values[col])
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	c.Data(status, "text/csv; charset=utf-8", buf.Bytes())
+	return nil
+}
+
+// csvCell menulis nilai skalar apa adanya dan nilai bersarang sebagai JSON
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *orderedMap, []interface{}:
+		raw, err := json.Marshal(toPlain(v))
+		if err != nil {
+			return ""
+		}
+		return string(raw)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ---------------------------
+// NORMALISASI PAYLOAD
+// ---------------------------
+// Semua format non-JSON berangkat dari representasi JSON payload, sehingga nama field
+// (json tag, omitempty, MarshalJSON) dan urutannya sama persis di setiap format.
+
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func toOrdered(payload any) (interface{}, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		m := &orderedMap{values: make(map[string]interface{})}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key)
+			m.values[key] = value
+		}
+		_, err := dec.Token() // '}'
+		return m, err
+	case '[':
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token() // ']'
+		return list, err
+	default:
+		return nil, fmt.Errorf("unexpected json delimiter %v", delim)
+	}
+}
+
+// toPlain mengubah hasil toOrdered ke map/slice biasa dengan angka int64/float64
+func toPlain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *orderedMap:
+		m := make(map[string]interface{}, len(v.keys))
+		for _, key := range v.keys {
+			m[key] = toPlain(v.values[key])
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = toPlain(item)
+		}
+		return list
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
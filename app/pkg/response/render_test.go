@@ -0,0 +1,90 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiatedFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name   string
+		query  string
+		accept string
+		want   string
+	}{
+		{"empty accept", "", "", "json"},
+		{"json", "", "application/json", "json"},
+		{"xml", "", "application/xml", "xml"},
+		{"problem xml", "", "application/problem+xml", "xml"},
+		{"problem json", "", "application/problem+json", "json"},
+		{"browser", "", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "json"},
+		{"q ordering", "", "application/json;q=0.5, text/csv", "csv"},
+		{"msgpack", "", "application/x-msgpack", "msgpack"},
+		{"wildcard", "", "*/*", "json"},
+		{"format param wins", "format=xml", "application/json", "xml"},
+		{"underscore format param wins", "_format=csv", "application/json", "csv"},
+		{"underscore format param before format", "_format=xml&format=csv", "", "xml"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/users?"+tc.query, nil)
+			if tc.accept != "" {
+				c.Request.Header.Set("Accept", tc.accept)
+			}
+
+			if got := NegotiatedFormat(c); got != tc.want {
+				t.Errorf("NegotiatedFormat() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name   string
+		query  string
+		accept string
+	}{
+		{"unsupported accept", "", "image/png"},
+		{"unregistered +xml", "", "application/atom+xml"},
+		{"unknown format param", "format=yaml", "application/json"},
+		{"unknown underscore format param", "_format=yaml", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/users?"+tc.query, nil)
+			if tc.accept != "" {
+				c.Request.Header.Set("Accept", tc.accept)
+			}
+
+			Success(c, "ok", gin.H{"id": 1})
+
+			if w.Code != http.StatusNotAcceptable {
+				t.Fatalf("status = %d, want 406", w.Code)
+			}
+			var body Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body is not a JSON envelope: %v", err)
+			}
+			if body.Status != "error" || body.Code != http.StatusNotAcceptable || body.ErrorCode != CodeNotAcceptable {
+				t.Errorf("envelope = %+v, want error 406 %s", body, CodeNotAcceptable)
+			}
+			if body.Data != nil {
+				t.Errorf("406 must not leak the original data: %v", body.Data)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package response
 
 import (
+	"strings"
+
+	"response-std/app/pkg/i18n"
 	"response-std/config"
 	"response-std/libs/external/services"
+	"response-std/libs/requestid"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,13 +18,36 @@ func InitLogger() {
 }
 
 type Response struct {
-	Status  string `json:"status"`
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
+	Status    string    `json:"status"`
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	Message   string    `json:"message"`
+	Data      any       `json:"data,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Meta      *Meta     `json:"meta,omitempty"`
 }
 
 func respond(c *gin.Context, statusCode int, message string, data any) {
+	respondWithCode(c, statusCode, message, data, "")
+}
+
+// message boleh berupa translation key (mis. "auth.login_success"), diterjemahkan sesuai locale request
+func respondWithCode(c *gin.Context, statusCode int, message string, data any, errorCode ErrorCode) {
+	message = i18n.T(c, message)
+
+	if statusCode >= 400 && wantsProblem(c) {
+		var extensions map[string]interface{}
+		if errorCode != "" {
+			extensions = map[string]interface{}{"error_code": errorCode}
+		}
+		problemRespond(c, newProblem(c, statusCode, message, extensions))
+		return
+	}
+
+	if writeETag(c, statusCode, data) {
+		return
+	}
+
 	var status string
 	if statusCode >= 200 && statusCode <= 299 {
 		status = "success"
@@ -28,13 +55,16 @@ func respond(c *gin.Context, statusCode int, message string, data any) {
 		status = "error"
 	}
 	response := Response{
-		Status:  status,
-		Code:    statusCode,
-		Message: message,
-		Data:    data,
+		Status:    status,
+		Code:      statusCode,
+		ErrorCode: errorCode,
+		Message:   message,
+		Data:      data,
+		RequestID: requestid.FromContext(c),
+		Meta:      metaOf(c),
 	}
 
-	c.JSON(statusCode, response)
+	render(c, statusCode, response)
 }
 
 func Success(c *gin.Context, message string, data any) {
@@ -54,8 +84,12 @@ func NoContent(c *gin.Context) {
 }
 
 // Error responses with log level checking
+// Jika err membawa ErrorCode (lihat WithCode), code tersebut ditulis sebagai error_code.
 func Error(c *gin.Context, code int, message string, err error, logPrefix string, level ...string) {
+	errorCode := errorCodeOf(err)
+
 	if log != nil {
+		log := log.WithContext(c)
 		APP_NAME := config.ENV.APP_NAME
 		Prefix := logPrefix
 
@@ -78,6 +112,9 @@ func Error(c *gin.Context, code int, message string, err error, logPrefix string
 			"method":      c.Request.Method,
 			"client_ip":   c.ClientIP(),
 		}
+		if errorCode != "" {
+			fields["error_code"] = errorCode
+		}
 
 		// Log based on specified level
 		switch logLevel {
@@ -96,7 +133,29 @@ func Error(c *gin.Context, code int, message string, err error, logPrefix string
 			log.Error(mssg, err, fields)
 		}
 	}
-	respond(c, code, message, nil)
+	respondWithCode(c, code, message, nil, errorCode)
+}
+
+// Fail merespons berdasarkan katalog error code: status & message diambil dari ErrorCodeInfo.
+// Code yang tidak terdaftar dianggap 500.
+func Fail(c *gin.Context, code ErrorCode, err error, logPrefix ...string) {
+	info, ok := LookupErrorCode(code)
+	if !ok {
+		info = ErrorCodeInfo{Code: code, Status: 500, Message: "Internal server error occurred"}
+	}
+
+	// Pesan terjemahan untuk code ada di katalog i18n dengan key "errors.<CODE>"
+	message := info.Message
+	if key := "errors." + string(code); i18n.Has(i18n.Locale(c), key) {
+		message = key
+	}
+
+	level := "warn"
+	if info.Status >= 500 {
+		level = "critical"
+	}
+
+	Error(c, info.Status, message, WithCode(code, err), getLogPrefix(logPrefix, string(code)), level)
 }
 
 // Client Error Responses (4xx) - typically logged as warnings
@@ -121,10 +180,16 @@ func UnprocessableEntity(c *gin.Context, message string, err error, logPrefix ..
 }
 
 func UnprocessableValidation(c *gin.Context, message string, err error, errInterface map[string]interface{}, logPrefix ...string) {
-	validationErrorRespond(c, message, errInterface)
+	errorCode := errorCodeOf(err)
+	if errorCode == "" {
+		errorCode = CodeValidationFailed
+	}
+
+	validationErrorRespond(c, message, errorCode, errInterface)
 	if log != nil {
-		log.Warn(message, map[string]interface{}{
+		log.WithContext(c).Warn(message, map[string]interface{}{
 			"error":      err,
+			"error_code": errorCode,
 			"message":    errInterface,
 			"request":    c.Request.URL.Path,
 			"method":     c.Request.Method,
@@ -146,6 +211,14 @@ func PreconditionFailed(c *gin.Context, message string, err error, logPrefix ...
 	Error(c, 412, message, err, getLogPrefix(logPrefix, "Precondition Failed"), "warn")
 }
 
+func Locked(c *gin.Context, message string, err error, logPrefix ...string) {
+	Error(c, 423, message, err, getLogPrefix(logPrefix, "Locked"), "warn")
+}
+
+func RequestEntityTooLarge(c *gin.Context, message string, err error, logPrefix ...string) {
+	Error(c, 413, message, err, getLogPrefix(logPrefix, "Request Entity Too Large"), "warn")
+}
+
 func RequestTimeout(c *gin.Context, message string, err error, logPrefix ...string) {
 	Error(c, 408, message, err, getLogPrefix(logPrefix, "Request Timeout"), "warn")
 }
@@ -159,10 +232,41 @@ func InternalServerError(c *gin.Context, message string, err error, logPrefix ..
 	Error(c, 500, message, err, getLogPrefix(logPrefix, "Internal Server Error"), "critical")
 }
 
+// Panic merespons 500 untuk panic yang sudah di-recover & di-log oleh middleware (tidak log ulang).
+// Di development detail panic dan stack trace ikut di body agar mudah di-debug.
+func Panic(c *gin.Context, recovered error, stack []byte) {
+	var debug map[string]interface{}
+	if config.ENV != nil && config.ENV.Environment == "development" {
+		debug = map[string]interface{}{
+			"panic": recovered.Error(),
+			"stack": strings.Split(strings.TrimSpace(string(stack)), "\n"),
+		}
+	}
+
+	if wantsProblem(c) {
+		extensions := map[string]interface{}{"error_code": CodeInternalError}
+		for k, v := range debug {
+			extensions[k] = v
+		}
+		problemRespond(c, newProblem(c, 500, i18n.T(c, "errors."+string(CodeInternalError)), extensions))
+		return
+	}
+
+	var data any // nil interface agar "data" tidak muncul di production
+	if debug != nil {
+		data = debug
+	}
+	respondWithCode(c, 500, "errors."+string(CodeInternalError), data, CodeInternalError)
+}
+
 func ServiceUnavailable(c *gin.Context, message string, err error, logPrefix ...string) {
 	Error(c, 503, message, err, getLogPrefix(logPrefix, "Service Unavailable"), "critical")
 }
 
+func GatewayTimeout(c *gin.Context, message string, err error, logPrefix ...string) {
+	Error(c, 504, message, err, getLogPrefix(logPrefix, "Gateway Timeout"), "error")
+}
+
 func getLogPrefix(logPrefix []string, defaultValue string) string {
 	if len(logPrefix) > 0 {
 		return logPrefix[0]
@@ -172,19 +276,35 @@ func getLogPrefix(logPrefix []string, defaultValue string) string {
 
 // ValidationErrorResponse is a custom error response for validation errors
 type ErrorResponse struct {
-	Status  string                 `json:"status"`
-	Code    int                    `json:"code"`
-	Message string                 `json:"message"`
-	Error   map[string]interface{} `json:"error"`
+	Status    string                 `json:"status"`
+	Code      int                    `json:"code"`
+	ErrorCode ErrorCode              `json:"error_code,omitempty"`
+	Message   string                 `json:"message"`
+	Error     map[string]interface{} `json:"error"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Meta      *Meta                  `json:"meta,omitempty"`
 }
 
-func validationErrorRespond(c *gin.Context, message string, err map[string]interface{}) {
+func validationErrorRespond(c *gin.Context, message string, errorCode ErrorCode, err map[string]interface{}) {
+	message = i18n.T(c, message)
+
+	if wantsProblem(c) {
+		problemRespond(c, newProblem(c, 422, message, map[string]interface{}{
+			"error_code": errorCode,
+			"errors":     err,
+		}))
+		return
+	}
+
 	response := ErrorResponse{
-		Status:  "error",
-		Code:    422,
-		Message: message,
-		Error:   err,
+		Status:    "error",
+		Code:      422,
+		ErrorCode: errorCode,
+		Message:   message,
+		Error:     err,
+		RequestID: requestid.FromContext(c),
+		Meta:      metaOf(c),
 	}
 
-	c.JSON(422, response)
+	render(c, 422, response)
 }
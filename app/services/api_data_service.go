@@ -1,13 +1,17 @@
 package services
 
 import (
+	"context"
 	"response-std/config"
 	"response-std/libs/external/services"
 	"sync"
 )
 
+const userDataURL = "https://dummyjson.com/users"
+
 type APIDataService struct {
 	client *services.APIClient
+	url    string
 }
 
 var (
@@ -27,10 +31,13 @@ func NewAPIDataService(cfg *config.Config) *APIDataService {
 
 	return &APIDataService{
 		client: services.NewAPIClient(cfg, log),
+		url:    userDataURL,
 	}
 }
 
-func (s *APIDataService) GetUserData() interface{} {
-	res := s.client.Get("https://dummyjson.com/users").Execute()
+// GetUserData mengambil data user dari API eksternal. ctx biasanya c.Request.Context(), sehingga
+// call ikut dibatalkan bersama request dan membawa X-Request-ID request masuk.
+func (s *APIDataService) GetUserData(ctx context.Context) interface{} {
+	res := s.client.Get(s.url).WithContext(ctx).Execute()
 	return res.Data
 }
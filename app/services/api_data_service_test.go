@@ -0,0 +1,51 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"response-std/app/http/middleware"
+	"response-std/config"
+	"response-std/libs/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetUserDataForwardsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var forwarded string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get(requestid.Header)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"users":[]}`))
+	}))
+	defer upstream.Close()
+
+	previous := config.ENV
+	config.ENV = &config.Config{LogLevel: "error", Environment: "test", RequestTimeout: time.Second}
+	defer func() { config.ENV = previous }()
+
+	svc := NewAPIDataService(config.ENV)
+	svc.url = upstream.URL
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.GET("/users", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"external": svc.GetUserData(c.Request.Context())})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(requestid.Header, "inbound-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d %s, want 200", w.Code, w.Body)
+	}
+	if forwarded != "inbound-123" {
+		t.Errorf("upstream %s = %q, want inbound-123", requestid.Header, forwarded)
+	}
+}
@@ -28,12 +28,130 @@ type Config struct {
 	API_BASE_URL   string        `mapstructure:"api_base_url" default:"http://localhost:5220/api/v1"`
 	BASE_URL       string        `mapstructure:"base_url" default:"http://localhost:5220"`
 
+	// Handler Timeout Configuration (inbound request, REQUEST_TIMEOUT hanya untuk outbound API client)
+	// Override per route: "METHOD /full/path=durasi" atau "/full/path=durasi", dipisah koma
+	HandlerTimeout       time.Duration `mapstructure:"handler_timeout" default:"30s"`
+	HandlerTimeoutRoutes []string      `mapstructure:"handler_timeout_routes" default:""`
+
+	// Body Limit & Compression Configuration (ukuran: 512KB, 1MB, ...)
+	// Override body limit per route group (prefix path): "/upload=10MB,/api/web=2MB"
+	BodyLimit          string   `mapstructure:"body_limit" default:"1MB"`
+	BodyLimitRoutes    []string `mapstructure:"body_limit_routes" default:""`
+	Compression        bool     `mapstructure:"compression" default:"true"`
+	CompressionMinSize string   `mapstructure:"compression_min_size" default:"1KB"`
+	CompressionTypes   []string `mapstructure:"compression_types" default:""`
+
 	// Log Channel Configuration
 	LogChannel         string `mapstructure:"log_channel" default:"file"`
 	DiscordWebhookURL  string `mapstructure:"discord_webhook_url" default:""`
 	DiscordMinLogLevel string `mapstructure:"discord_min_log_level" default:"error"`
 	LogToFile          bool   `mapstructure:"log_to_file" default:"true"`
 	LogDir             string `mapstructure:"log_dir" default:"logs"`
+
+	// Access Log Configuration
+	AccessLogSampleRate    float64       `mapstructure:"access_log_sample_rate" default:"1"`
+	AccessLogExcludePaths  []string      `mapstructure:"access_log_exclude_paths" default:"/api/v1/health"`
+	AccessLogSlowThreshold time.Duration `mapstructure:"access_log_slow_threshold" default:"1s"`
+
+	// Error Response Configuration
+	ErrorFormat        string `mapstructure:"error_format" default:"envelope"`
+	ProblemTypeBaseURL string `mapstructure:"problem_type_base_url" default:""`
+
+	// Localization Configuration
+	DefaultLocale  string `mapstructure:"default_locale" default:"id"`
+	FallbackLocale string `mapstructure:"fallback_locale" default:"en"`
+	LangDir        string `mapstructure:"lang_dir" default:""`
+
+	// Response Meta Configuration
+	ResponseMeta          bool     `mapstructure:"response_meta" default:"false"`
+	DeprecatedAPIVersions []string `mapstructure:"deprecated_api_versions" default:""`
+
+	// Rate Limit Configuration (format <limit>/<window>, mis. 5/1m)
+	RateLimitStore  string `mapstructure:"rate_limit_store" default:"memory"`
+	RateLimitGlobal string `mapstructure:"rate_limit_global" default:"600/1m"`
+	RateLimitLogin  string `mapstructure:"rate_limit_login" default:"5/1m"`
+	RateLimitUser   string `mapstructure:"rate_limit_user" default:"120/1m"`
+
+	// Proxy Configuration (IP/CIDR reverse proxy; X-Forwarded-For hanya dipercaya dari proxy ini)
+	TrustedProxies []string `mapstructure:"trusted_proxies" default:""`
+
+	// Redis Configuration
+	RedisAddr     string `mapstructure:"redis_addr" default:"localhost:6379"`
+	RedisPassword string `mapstructure:"redis_password" default:""`
+	RedisDB       int    `mapstructure:"redis_db" default:"0"`
+
+	// Login Lockout Configuration
+	LoginMaxAttempts      int64         `mapstructure:"login_max_attempts" default:"5"`
+	LoginMaxAttemptsPerIP int64         `mapstructure:"login_max_attempts_per_ip" default:"20"`
+	LoginAttemptWindow    time.Duration `mapstructure:"login_attempt_window" default:"15m"`
+	LoginLockoutDuration  time.Duration `mapstructure:"login_lockout_duration" default:"15m"`
+	LoginDelayBase        time.Duration `mapstructure:"login_delay_base" default:"1s"`
+	LoginDelayMax         time.Duration `mapstructure:"login_delay_max" default:"30s"`
+
+	// Idempotency Configuration (store: memory | database)
+	IdempotencyStore       string        `mapstructure:"idempotency_store" default:"memory"`
+	IdempotencyTTL         time.Duration `mapstructure:"idempotency_ttl" default:"24h"`
+	IdempotencyLockTimeout time.Duration `mapstructure:"idempotency_lock_timeout" default:"1m"`
+
+	// Personal Access Token Configuration
+	// last_used_at hanya ditulis ulang jika sudah lewat TOKEN_LAST_USED_INTERVAL (debounce)
+	TokenLastUsedInterval time.Duration `mapstructure:"token_last_used_interval" default:"1m"`
+	TokenPruneDays        int           `mapstructure:"token_prune_days" default:"90"`
+
+	// Session Configuration: access token (login / refresh) dan refresh token sekali pakai
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl" default:"15m"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl" default:"720h"`
+
+	// Auth Guard & JWT Configuration (guard: token | jwt | both)
+	// JWT_VERIFY_KEYS: "kid=secret" untuk HS256, "kid=path/public.pem" untuk RS256/EdDSA (key lama saat rotasi)
+	AuthGuard        string   `mapstructure:"auth_guard" default:"token"`
+	JWTAlgorithm     string   `mapstructure:"jwt_algorithm" default:"HS256"`
+	JWTKeyID         string   `mapstructure:"jwt_key_id" default:"v1"`
+	JWTPrivateKey    string   `mapstructure:"jwt_private_key" default:""`
+	JWTVerifyKeys    []string `mapstructure:"jwt_verify_keys" default:""`
+	JWTIssuer        string   `mapstructure:"jwt_issuer" default:""`
+	JWTAudience      string   `mapstructure:"jwt_audience" default:""`
+	JWTDenylistStore string   `mapstructure:"jwt_denylist_store" default:"memory"`
+
+	// Security Headers Configuration (kosong = default per ENVIRONMENT, "off" = header tidak dikirim)
+	SecurityHeaders           bool   `mapstructure:"security_headers" default:"true"`
+	SecurityHSTS              string `mapstructure:"security_hsts" default:""`
+	SecurityFrameOptions      string `mapstructure:"security_frame_options" default:""`
+	SecurityReferrerPolicy    string `mapstructure:"security_referrer_policy" default:""`
+	SecurityPermissionsPolicy string `mapstructure:"security_permissions_policy" default:""`
+	SecurityCSP               string `mapstructure:"security_csp" default:""`
+	SecurityCSPReportOnly     bool   `mapstructure:"security_csp_report_only" default:"false"`
+
+	// CORS Configuration (list dipisah koma, override per version: CORS_<VERSION>_ALLOWED_ORIGINS, dst)
+	CORSAllowAll         bool          `mapstructure:"cors_allow_all" default:"false"`
+	CORSAllowedOrigins   []string      `mapstructure:"cors_allowed_origins" default:""`
+	CORSAllowedMethods   []string      `mapstructure:"cors_allowed_methods" default:""`
+	CORSAllowedHeaders   []string      `mapstructure:"cors_allowed_headers" default:""`
+	CORSExposedHeaders   []string      `mapstructure:"cors_exposed_headers" default:""`
+	CORSAllowCredentials bool          `mapstructure:"cors_allow_credentials" default:"false"`
+	CORSMaxAge           time.Duration `mapstructure:"cors_max_age" default:"12h"`
+}
+
+// SecurityHeadersConfig adalah nilai header keamanan efektif, string kosong = tidak dikirim
+type SecurityHeadersConfig struct {
+	HSTS               string
+	ContentTypeOptions string
+	FrameOptions       string
+	ReferrerPolicy     string
+	PermissionsPolicy  string
+	CSP                string
+	CSPReportOnly      bool
+}
+
+// CORSConfig adalah policy CORS efektif untuk satu API version
+type CORSConfig struct {
+	AllowAll         bool
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
 }
 
 var ENV *Config
@@ -58,6 +176,21 @@ func InitConfig() {
 	viper.BindEnv("api_base_url", "API_BASE_URL")
 	viper.BindEnv("base_url", "BASE_URL")
 
+	// Handler Timeout bindings
+	viper.BindEnv("handler_timeout", "HANDLER_TIMEOUT")
+	viper.BindEnv("handler_timeout_routes", "HANDLER_TIMEOUT_ROUTES")
+	viper.SetDefault("handler_timeout", "30s")
+
+	// Body Limit & Compression bindings
+	viper.BindEnv("body_limit", "BODY_LIMIT")
+	viper.BindEnv("body_limit_routes", "BODY_LIMIT_ROUTES")
+	viper.BindEnv("compression", "COMPRESSION")
+	viper.BindEnv("compression_min_size", "COMPRESSION_MIN_SIZE")
+	viper.BindEnv("compression_types", "COMPRESSION_TYPES")
+	viper.SetDefault("body_limit", "1MB")
+	viper.SetDefault("compression", true)
+	viper.SetDefault("compression_min_size", "1KB")
+
 	// Log Channel bindings
 	viper.BindEnv("log_channel", "LOG_CHANNEL")
 	viper.BindEnv("discord_webhook_url", "DISCORD_WEBHOOK_URL")
@@ -65,6 +198,103 @@ func InitConfig() {
 	viper.BindEnv("log_to_file", "LOG_TO_FILE")
 	viper.BindEnv("log_dir", "LOG_DIR")
 
+	// Access Log bindings
+	viper.BindEnv("access_log_sample_rate", "ACCESS_LOG_SAMPLE_RATE")
+	viper.BindEnv("access_log_exclude_paths", "ACCESS_LOG_EXCLUDE_PATHS")
+	viper.BindEnv("access_log_slow_threshold", "ACCESS_LOG_SLOW_THRESHOLD")
+	viper.SetDefault("access_log_sample_rate", 1)
+	viper.SetDefault("access_log_exclude_paths", "/api/v1/health")
+	viper.SetDefault("access_log_slow_threshold", "1s")
+
+	// Error Response bindings
+	viper.BindEnv("error_format", "ERROR_FORMAT")
+	viper.BindEnv("problem_type_base_url", "PROBLEM_TYPE_BASE_URL")
+
+	// Localization bindings
+	viper.BindEnv("default_locale", "DEFAULT_LOCALE")
+	viper.BindEnv("fallback_locale", "FALLBACK_LOCALE")
+	viper.BindEnv("lang_dir", "LANG_DIR")
+
+	// Response Meta bindings
+	viper.BindEnv("response_meta", "RESPONSE_META")
+	viper.BindEnv("deprecated_api_versions", "DEPRECATED_API_VERSIONS")
+
+	// Rate Limit bindings
+	viper.BindEnv("rate_limit_store", "RATE_LIMIT_STORE")
+	viper.BindEnv("rate_limit_global", "RATE_LIMIT_GLOBAL")
+	viper.BindEnv("rate_limit_login", "RATE_LIMIT_LOGIN")
+	viper.BindEnv("rate_limit_user", "RATE_LIMIT_USER")
+
+	// Proxy bindings
+	viper.BindEnv("trusted_proxies", "TRUSTED_PROXIES")
+
+	// Redis bindings
+	viper.BindEnv("redis_addr", "REDIS_ADDR")
+	viper.BindEnv("redis_password", "REDIS_PASSWORD")
+	viper.BindEnv("redis_db", "REDIS_DB")
+
+	// Login Lockout bindings
+	viper.BindEnv("login_max_attempts", "LOGIN_MAX_ATTEMPTS")
+	viper.BindEnv("login_max_attempts_per_ip", "LOGIN_MAX_ATTEMPTS_PER_IP")
+	viper.BindEnv("login_attempt_window", "LOGIN_ATTEMPT_WINDOW")
+	viper.BindEnv("login_lockout_duration", "LOGIN_LOCKOUT_DURATION")
+	viper.BindEnv("login_delay_base", "LOGIN_DELAY_BASE")
+	viper.BindEnv("login_delay_max", "LOGIN_DELAY_MAX")
+
+	// Idempotency bindings
+	viper.BindEnv("idempotency_store", "IDEMPOTENCY_STORE")
+	viper.BindEnv("idempotency_ttl", "IDEMPOTENCY_TTL")
+	viper.BindEnv("idempotency_lock_timeout", "IDEMPOTENCY_LOCK_TIMEOUT")
+	viper.SetDefault("idempotency_ttl", "24h")
+	viper.SetDefault("idempotency_lock_timeout", "1m")
+
+	// Personal Access Token bindings
+	viper.BindEnv("token_last_used_interval", "TOKEN_LAST_USED_INTERVAL")
+	viper.BindEnv("token_prune_days", "TOKEN_PRUNE_DAYS")
+	viper.SetDefault("token_last_used_interval", "1m")
+	viper.SetDefault("token_prune_days", 90)
+
+	// Session bindings
+	viper.BindEnv("access_token_ttl", "ACCESS_TOKEN_TTL")
+	viper.BindEnv("refresh_token_ttl", "REFRESH_TOKEN_TTL")
+	viper.SetDefault("access_token_ttl", "15m")
+	viper.SetDefault("refresh_token_ttl", "720h")
+
+	// Auth Guard & JWT bindings
+	viper.BindEnv("auth_guard", "AUTH_GUARD")
+	viper.BindEnv("jwt_algorithm", "JWT_ALGORITHM")
+	viper.BindEnv("jwt_key_id", "JWT_KEY_ID")
+	viper.BindEnv("jwt_private_key", "JWT_PRIVATE_KEY")
+	viper.BindEnv("jwt_verify_keys", "JWT_VERIFY_KEYS")
+	viper.BindEnv("jwt_issuer", "JWT_ISSUER")
+	viper.BindEnv("jwt_audience", "JWT_AUDIENCE")
+	viper.BindEnv("jwt_denylist_store", "JWT_DENYLIST_STORE")
+	viper.SetDefault("auth_guard", "token")
+	viper.SetDefault("jwt_algorithm", "HS256")
+	viper.SetDefault("jwt_key_id", "v1")
+	viper.SetDefault("jwt_denylist_store", "memory")
+
+	// Security Headers bindings
+	viper.BindEnv("security_headers", "SECURITY_HEADERS")
+	viper.BindEnv("security_hsts", "SECURITY_HSTS")
+	viper.BindEnv("security_frame_options", "SECURITY_FRAME_OPTIONS")
+	viper.BindEnv("security_referrer_policy", "SECURITY_REFERRER_POLICY")
+	viper.BindEnv("security_permissions_policy", "SECURITY_PERMISSIONS_POLICY")
+	viper.BindEnv("security_csp", "SECURITY_CSP")
+	viper.BindEnv("security_csp_report_only", "SECURITY_CSP_REPORT_ONLY")
+	viper.SetDefault("security_headers", true)
+
+	// CORS bindings
+	viper.BindEnv("cors_allow_all", "CORS_ALLOW_ALL")
+	viper.BindEnv("cors_allowed_origins", "CORS_ALLOWED_ORIGINS")
+	viper.BindEnv("cors_allowed_methods", "CORS_ALLOWED_METHODS")
+	viper.BindEnv("cors_allowed_headers", "CORS_ALLOWED_HEADERS")
+	viper.BindEnv("cors_exposed_headers", "CORS_EXPOSED_HEADERS")
+	viper.BindEnv("cors_allow_credentials", "CORS_ALLOW_CREDENTIALS")
+	viper.BindEnv("cors_max_age", "CORS_MAX_AGE")
+	viper.SetDefault("cors_allow_credentials", false)
+	viper.SetDefault("cors_max_age", "12h")
+
 	if err := viper.ReadInConfig(); err != nil {
 		panic(fmt.Errorf("fatal error config file: %w", err))
 	}
@@ -72,11 +302,147 @@ func InitConfig() {
 	rawVersions := viper.GetString("api_version")
 	viper.Set("api_version", strings.Split(rawVersions, ","))
 
+	for _, key := range []string{
+		"deprecated_api_versions",
+		"access_log_exclude_paths",
+		"handler_timeout_routes",
+		"body_limit_routes",
+		"compression_types",
+		"jwt_verify_keys",
+		"trusted_proxies",
+		"cors_allowed_origins",
+		"cors_allowed_methods",
+		"cors_allowed_headers",
+		"cors_exposed_headers",
+	} {
+		viper.Set(key, splitList(viper.GetString(key)))
+	}
+
 	if err := viper.Unmarshal(&ENV); err != nil {
 		panic(fmt.Errorf("unable to decode into struct: %w", err))
 	}
 }
 
+// splitList memecah "a, b,c" menjadi []string{"a", "b", "c"}; string kosong -> nil
+func splitList(raw string) []string {
+	var list []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+// AllowsAnyOrigin true jika policy menerima origin apa pun (CORS_ALLOW_ALL atau "*")
+func (p CORSConfig) AllowsAnyOrigin() bool {
+	if p.AllowAll {
+		return true
+	}
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS mengembalikan policy CORS untuk version (key router.RouteRegistry).
+// Nilai CORS_<VERSION>_* (mis. CORS_WEB_ALLOWED_ORIGINS) menimpa nilai global jika diisi.
+// Development tanpa CORS_ALLOWED_ORIGINS otomatis allow-all.
+// Dipanggil sekali per version saat middleware CORS dibuat.
+func (c *Config) CORS(version string) CORSConfig {
+	cfg := CORSConfig{
+		AllowAll:         c.CORSAllowAll,
+		AllowedOrigins:   c.CORSAllowedOrigins,
+		AllowedMethods:   c.CORSAllowedMethods,
+		AllowedHeaders:   c.CORSAllowedHeaders,
+		ExposedHeaders:   c.CORSExposedHeaders,
+		AllowCredentials: c.CORSAllowCredentials,
+		MaxAge:           c.CORSMaxAge,
+	}
+
+	if version != "" {
+		override := func(name string) string {
+			key := "cors_" + strings.ToLower(version) + "_" + name
+			viper.BindEnv(key, strings.ToUpper(key))
+			return viper.GetString(key)
+		}
+
+		if v := override("allow_all"); v != "" {
+			cfg.AllowAll = strings.EqualFold(v, "true")
+		}
+		if v := override("allowed_origins"); v != "" {
+			cfg.AllowedOrigins = splitList(v)
+		}
+		if v := override("allowed_methods"); v != "" {
+			cfg.AllowedMethods = splitList(v)
+		}
+		if v := override("allowed_headers"); v != "" {
+			cfg.AllowedHeaders = splitList(v)
+		}
+		if v := override("exposed_headers"); v != "" {
+			cfg.ExposedHeaders = splitList(v)
+		}
+		if v := override("allow_credentials"); v != "" {
+			cfg.AllowCredentials = strings.EqualFold(v, "true")
+		}
+		if v := override("max_age"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil {
+				cfg.MaxAge = d
+			}
+		}
+	}
+
+	if len(cfg.AllowedOrigins) == 0 && c.Environment == "development" {
+		cfg.AllowAll = true
+	}
+
+	return cfg
+}
+
+// ErrorFormatFor mengembalikan format error untuk version (key router.RouteRegistry).
+// ERROR_FORMAT_<VERSION> (mis. ERROR_FORMAT_WEB=problem) menimpa ERROR_FORMAT jika diisi.
+// Dipanggil sekali saat route group dibuat.
+func (c *Config) ErrorFormatFor(version string) string {
+	key := "error_format_" + strings.ToLower(version)
+	viper.BindEnv(key, strings.ToUpper(key))
+	if v := strings.TrimSpace(viper.GetString(key)); v != "" {
+		return strings.ToLower(v)
+	}
+	return strings.ToLower(c.ErrorFormat)
+}
+
+// SecurityHeadersPolicy mengembalikan header keamanan efektif. Nilai .env menang; jika kosong
+// dipakai default production (ketat, termasuk HSTS & CSP) atau development (tanpa HSTS & CSP
+// agar localhost http dan tool seperti swagger tetap jalan). "off" mematikan satu header.
+func (c *Config) SecurityHeadersPolicy() SecurityHeadersConfig {
+	production := c.Environment == "production"
+
+	pick := func(value, prodDefault, devDefault string) string {
+		switch {
+		case strings.EqualFold(value, "off"):
+			return ""
+		case value != "":
+			return value
+		case production:
+			return prodDefault
+		default:
+			return devDefault
+		}
+	}
+
+	return SecurityHeadersConfig{
+		HSTS:               pick(c.SecurityHSTS, "max-age=31536000; includeSubDomains", ""),
+		ContentTypeOptions: "nosniff",
+		FrameOptions:       pick(c.SecurityFrameOptions, "DENY", "SAMEORIGIN"),
+		ReferrerPolicy:     pick(c.SecurityReferrerPolicy, "strict-origin-when-cross-origin", "strict-origin-when-cross-origin"),
+		PermissionsPolicy:  pick(c.SecurityPermissionsPolicy, "camera=(), microphone=(), geolocation=()", "camera=(), microphone=(), geolocation=()"),
+		CSP:                pick(c.SecurityCSP, "default-src 'none'; frame-ancestors 'none'", ""),
+		CSPReportOnly:      c.SecurityCSPReportOnly,
+	}
+}
+
 // Helper methods for log channel configuration
 // IsFileLoggingEnabled checks if file logging is enabled
 func (c *Config) IsFileLoggingEnabled() bool {
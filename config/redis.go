@@ -0,0 +1,25 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	redisClient *redis.Client
+	redisOnce   sync.Once
+)
+
+// RedisClient mengembalikan client Redis bersama (dibuat saat pertama kali dipakai).
+// Dipakai oleh store yang memilih "redis" (rate limit, login lockout).
+func RedisClient() *redis.Client {
+	redisOnce.Do(func() {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     ENV.RedisAddr,
+			Password: ENV.RedisPassword,
+			DB:       ENV.RedisDB,
+		})
+	})
+	return redisClient
+}
@@ -0,0 +1,2 @@
+-- Preferensi bahasa user untuk respons API (id, en, ...)
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NULL AFTER remember_token;
@@ -0,0 +1,12 @@
+-- Create idempotency_keys table
+CREATE TABLE idempotency_keys (
+    `key` VARCHAR(64) NOT NULL PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    response_headers TEXT NULL,
+    response_body LONGBLOB NULL,
+    completed_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NULL,
+    INDEX idempotency_keys_expires_at_index (expires_at)
+);
@@ -0,0 +1,2 @@
+-- Kembalikan abilities ke format lama ['*']
+UPDATE personal_access_tokens SET abilities = '[''*'']' WHERE abilities = '["*"]';
@@ -0,0 +1,2 @@
+-- Ubah abilities format lama ['*'] menjadi JSON
+UPDATE personal_access_tokens SET abilities = '["*"]' WHERE abilities = '[''*'']' OR abilities IS NULL;
@@ -0,0 +1,8 @@
+-- Hapus metadata klien dari personal_access_tokens
+ALTER TABLE personal_access_tokens
+    DROP INDEX personal_access_tokens_expires_at_index,
+    DROP INDEX personal_access_tokens_last_used_at_index,
+    DROP COLUMN last_used_user_agent,
+    DROP COLUMN last_used_ip,
+    DROP COLUMN created_user_agent,
+    DROP COLUMN created_ip;
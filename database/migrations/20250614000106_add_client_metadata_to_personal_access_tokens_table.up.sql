@@ -0,0 +1,8 @@
+-- Tambah metadata klien (IP & user agent) saat token dibuat dan terakhir dipakai
+ALTER TABLE personal_access_tokens
+    ADD COLUMN created_ip VARCHAR(45) NULL AFTER abilities,
+    ADD COLUMN created_user_agent VARCHAR(512) NULL AFTER created_ip,
+    ADD COLUMN last_used_ip VARCHAR(45) NULL AFTER created_user_agent,
+    ADD COLUMN last_used_user_agent VARCHAR(512) NULL AFTER last_used_ip,
+    ADD INDEX personal_access_tokens_last_used_at_index (last_used_at),
+    ADD INDEX personal_access_tokens_expires_at_index (expires_at);
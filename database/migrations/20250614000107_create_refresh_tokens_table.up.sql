@@ -0,0 +1,21 @@
+-- Create refresh_tokens table
+CREATE TABLE refresh_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    family CHAR(36) NOT NULL,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    access_token_id BIGINT UNSIGNED NULL,
+    name VARCHAR(255) NOT NULL,
+    abilities TEXT NULL,
+    created_ip VARCHAR(45) NULL,
+    created_user_agent VARCHAR(512) NULL,
+    used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NULL,
+    INDEX refresh_tokens_user_id_index (user_id),
+    INDEX refresh_tokens_family_index (family),
+    INDEX refresh_tokens_access_token_id_index (access_token_id),
+    INDEX refresh_tokens_expires_at_index (expires_at)
+);
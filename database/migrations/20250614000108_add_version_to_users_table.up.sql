@@ -0,0 +1,2 @@
+-- Versi baris untuk optimistic concurrency (ETag / If-Match), naik setiap update
+ALTER TABLE users ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1 AFTER locale;
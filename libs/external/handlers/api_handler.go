@@ -44,7 +44,7 @@ func (h *APIHandler) HealthCheck(c *gin.Context) {
 // Example usage in your service/controller
 func (h *APIHandler) ExampleUsage(c *gin.Context) {
 	// Example 1: Simple GET request
-	resp1 := h.apiClient.Get("https://dummyjson.com/users").Execute()
+	resp1 := h.apiClient.Get("https://dummyjson.com/users").WithContext(c.Request.Context()).Execute()
 
 	// Return one of the responses as example
 	if resp1.Success {
@@ -94,6 +94,9 @@ func (h *APIHandler) ProxyRequest(c *gin.Context) {
 		return
 	}
 
+	// Teruskan request ID klien ke service tujuan
+	builder = builder.WithContext(c.Request.Context())
+
 	// Add headers if provided
 	if proxyReq.Headers != nil {
 		builder = builder.WithHeaders(proxyReq.Headers)
@@ -10,6 +10,7 @@ import (
 
 	"response-std/config"
 	"response-std/libs/external/requests"
+	"response-std/libs/requestid"
 	"response-std/libs/responses"
 
 	"github.com/go-resty/resty/v2"
@@ -31,6 +32,7 @@ type RequestBuilder struct {
 	body        interface{}
 	timeout     *time.Duration
 	requestID   string
+	ctx         context.Context
 }
 
 func NewAPIClient(cfg *config.Config, logger *Logger) *APIClient {
@@ -44,6 +46,17 @@ func NewAPIClient(cfg *config.Config, logger *Logger) *APIClient {
 		"Accept": "application/json",
 	})
 
+	// Teruskan X-Request-ID dari context request masuk ke service tujuan
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		if req.Header.Get(requestid.Header) != "" {
+			return nil
+		}
+		if id := requestid.FromContext(req.Context()); id != "" {
+			req.SetHeader(requestid.Header, id)
+		}
+		return nil
+	})
+
 	return &APIClient{
 		client: client,
 		logger: logger,
@@ -226,6 +239,16 @@ func (rb *RequestBuilder) WithCustomHeader(key, value string) *RequestBuilder {
 	return rb
 }
 
+// WithContext mengikat request ke ctx (biasanya c.Request.Context()). Request ID dari ctx
+// dipakai sebagai request_id call ini dan dikirim sebagai header X-Request-ID.
+func (rb *RequestBuilder) WithContext(ctx context.Context) *RequestBuilder {
+	rb.ctx = ctx
+	if id := requestid.FromContext(ctx); id != "" {
+		rb.requestID = id
+	}
+	return rb
+}
+
 // Execute the request
 func (rb *RequestBuilder) Execute() *responses.APIResponse {
 	startTime := time.Now()
@@ -248,6 +271,10 @@ func (rb *RequestBuilder) Execute() *responses.APIResponse {
 	// Clone client for this request to avoid race conditions
 	reqClient := rb.client.client.R()
 
+	if rb.ctx != nil {
+		reqClient.SetContext(rb.ctx)
+	}
+
 	// Set custom timeout if provided
 	if rb.timeout != nil {
 		timeoutCtx, cancel := context.WithTimeout(reqClient.Context(), *rb.timeout)
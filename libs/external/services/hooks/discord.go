@@ -99,12 +99,12 @@ func (hook *DiscordHook) createEmbed(entry *logrus.Entry) Embed {
 	// Add basic fields
 	if entry.Data != nil {
 		for key, value := range entry.Data {
-			if key == "error" {
-				continue // Handle error separately
+			if key == "error" || key == "request_id" || key == "stack" {
+				continue // Handle error, request_id & stack separately
 			}
 			fields = append(fields, Field{
 				Name:   strings.Title(strings.ReplaceAll(key, "_", " ")),
-				Value:  fmt.Sprintf("%v", value),
+				Value:  truncate(fmt.Sprintf("%v", value), maxFieldValue),
 				Inline: true,
 			})
 		}
@@ -114,20 +114,22 @@ func (hook *DiscordHook) createEmbed(entry *logrus.Entry) Embed {
 	if err, ok := entry.Data["error"]; ok && err != nil {
 		fields = append(fields, Field{
 			Name:   "Error",
-			Value:  fmt.Sprintf("```%v```", err),
+			Value:  codeBlock(fmt.Sprintf("%v", err)),
 			Inline: false,
 		})
 	}
 
+	if stack, ok := entry.Data["stack"]; ok && stack != nil {
+		fields = append(fields, stackField(fmt.Sprintf("%v", stack)))
+	}
+
 	embed := Embed{
 		Title:       fmt.Sprintf("%s - %s", hook.AppName, strings.ToUpper(entry.Level.String())),
 		Description: entry.Message,
 		Color:       color,
 		Fields:      fields,
-		Footer: Footer{
-			Text: fmt.Sprintf("Environment: %s", config.ENV.Environment),
-		},
-		Timestamp: entry.Time,
+		Footer:      footer(entry.Data),
+		Timestamp:   entry.Time,
 	}
 
 	return embed
@@ -199,12 +201,12 @@ func SendDiscordMessage(webhookURL, appName, level, message string, data map[str
 
 	var fields []Field
 	for key, value := range data {
-		if key == "error" {
+		if key == "error" || key == "request_id" || key == "stack" {
 			continue
 		}
 		fields = append(fields, Field{
 			Name:   strings.Title(strings.ReplaceAll(key, "_", " ")),
-			Value:  fmt.Sprintf("%v", value),
+			Value:  truncate(fmt.Sprintf("%v", value), maxFieldValue),
 			Inline: true,
 		})
 	}
@@ -212,20 +214,22 @@ func SendDiscordMessage(webhookURL, appName, level, message string, data map[str
 	if errVal, ok := data["error"]; ok && errVal != nil {
 		fields = append(fields, Field{
 			Name:   "Error",
-			Value:  fmt.Sprintf("```%v```", errVal),
+			Value:  codeBlock(fmt.Sprintf("%v", errVal)),
 			Inline: false,
 		})
 	}
 
+	if stack, ok := data["stack"]; ok && stack != nil {
+		fields = append(fields, stackField(fmt.Sprintf("%v", stack)))
+	}
+
 	embed := Embed{
 		Title:       fmt.Sprintf("%s - %s", appName, strings.ToUpper(level)),
 		Description: message,
 		Color:       color,
 		Fields:      fields,
-		Footer: Footer{
-			Text: fmt.Sprintf("Environment: %s", config.ENV.Environment),
-		},
-		Timestamp: time.Now(),
+		Footer:      footer(data),
+		Timestamp:   time.Now(),
 	}
 
 	payload := DiscordPayload{
@@ -235,6 +239,43 @@ func SendDiscordMessage(webhookURL, appName, level, message string, data map[str
 	return sendToWebhook(webhookURL, payload)
 }
 
+// Discord menolak embed field dengan value > 1024 karakter
+const maxFieldValue = 1024
+
+// stackField menampilkan stack trace yang dipotong: frame runtime/debug & recovery dibuang,
+// sisanya diambil dari atas (frame terdekat ke panic) sampai batas field Discord.
+func stackField(stack string) Field {
+	if i := strings.Index(stack, "\npanic("); i >= 0 {
+		stack = stack[i+1:]
+	}
+	return Field{
+		Name:   "Stack Trace",
+		Value:  codeBlock(stack),
+		Inline: false,
+	}
+}
+
+func codeBlock(text string) string {
+	return "```" + truncate(text, maxFieldValue-6) + "```"
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	const marker = "\n...(truncated)"
+	return text[:limit-len(marker)] + marker
+}
+
+// footer menampilkan environment dan request ID (jika ada) agar embed bisa dicocokkan dengan log
+func footer(data map[string]interface{}) Footer {
+	text := fmt.Sprintf("Environment: %s", config.ENV.Environment)
+	if id, ok := data["request_id"]; ok && id != nil && id != "" {
+		text += fmt.Sprintf(" | Request ID: %v", id)
+	}
+	return Footer{Text: text}
+}
+
 func sendToWebhook(webhookURL string, payload DiscordPayload) error {
 	if webhookURL == "" {
 		return fmt.Errorf("webhook URL is empty")
@@ -2,6 +2,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -11,12 +12,14 @@ import (
 	"response-std/config"
 	"response-std/libs/external/requests"
 	"response-std/libs/external/services/hooks"
+	"response-std/libs/requestid"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Logger struct {
 	logger *logrus.Logger
+	fields logrus.Fields // field yang selalu ikut di setiap entry (mis. request_id)
 }
 
 var AppLogger *Logger
@@ -116,6 +119,32 @@ func setupFileLogging(environment string) io.Writer {
 	return file
 }
 
+// WithContext mengembalikan logger yang menambahkan request_id dari ctx (request context
+// atau *gin.Context) ke setiap entry, termasuk embed Discord.
+// contoh penggunaan:
+// services.AppLogger.WithContext(c).Warn("Validation failed", fields)
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return l
+	}
+	return l.WithField(requestid.ContextKey, id)
+}
+
+// WithField mengembalikan logger baru dengan field tambahan yang selalu ikut di-log
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	fields := make(logrus.Fields, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return &Logger{logger: l.logger, fields: fields}
+}
+
+func (l *Logger) entry(fields map[string]interface{}) *logrus.Entry {
+	return l.logger.WithFields(l.fields).WithFields(fields)
+}
+
 func (l *Logger) LogRequest(requestLog *requests.RequestLog) {
 	fields := logrus.Fields{
 		"request_id":  requestLog.ID,
@@ -129,11 +158,11 @@ func (l *Logger) LogRequest(requestLog *requests.RequestLog) {
 
 	// Log request - level ditentukan berdasarkan status code
 	if requestLog.StatusCode >= 500 {
-		l.logger.WithFields(fields).Error("API Request - Server Error")
+		l.entry(fields).Error("API Request - Server Error")
 	} else if requestLog.StatusCode >= 400 {
-		l.logger.WithFields(fields).Warn("API Request - Client Error")
+		l.entry(fields).Warn("API Request - Client Error")
 	} else {
-		l.logger.WithFields(fields).Info("API Request - Success")
+		l.entry(fields).Info("API Request - Success")
 	}
 }
 
@@ -141,7 +170,7 @@ func (l *Logger) Info(message string, fields map[string]interface{}) {
 	if fields == nil {
 		fields = make(map[string]interface{})
 	}
-	l.logger.WithFields(fields).Info(message)
+	l.entry(fields).Info(message)
 }
 
 func (l *Logger) Error(message string, err error, fields map[string]interface{}) {
@@ -151,21 +180,21 @@ func (l *Logger) Error(message string, err error, fields map[string]interface{})
 	if err != nil {
 		fields["error"] = err.Error()
 	}
-	l.logger.WithFields(fields).Error(message)
+	l.entry(fields).Error(message)
 }
 
 func (l *Logger) Debug(message string, fields map[string]interface{}) {
 	if fields == nil {
 		fields = make(map[string]interface{})
 	}
-	l.logger.WithFields(fields).Debug(message)
+	l.entry(fields).Debug(message)
 }
 
 func (l *Logger) Warn(message string, fields map[string]interface{}) {
 	if fields == nil {
 		fields = make(map[string]interface{})
 	}
-	l.logger.WithFields(fields).Warn(message)
+	l.entry(fields).Warn(message)
 }
 
 func (l *Logger) Critical(message string, err error, fields map[string]interface{}) {
@@ -175,5 +204,5 @@ func (l *Logger) Critical(message string, err error, fields map[string]interface
 	if err != nil {
 		fields["error"] = err.Error()
 	}
-	l.logger.WithFields(fields).Error("[CRITICAL] " + message)
+	l.entry(fields).Error("[CRITICAL] " + message)
 }
@@ -0,0 +1,51 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header dipakai untuk menerima, mengembalikan dan meneruskan request ID
+	Header = "X-Request-ID"
+
+	// ContextKey adalah key di gin.Context (c.GetString(requestid.ContextKey))
+	ContextKey = "request_id"
+)
+
+type ctxKey struct{}
+
+// ID dari klien hanya dipakai jika aman ditulis ke log/header (maks 128 karakter)
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// New membuat request ID baru (UUID v4)
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid true jika id boleh dipakai apa adanya
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// NewContext menyimpan request ID di context (dipakai untuk c.Request.Context() dan outbound call)
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext mengambil request ID dari context.Context biasa maupun *gin.Context
+// (gin.Context.Value membaca c.Keys untuk key bertipe string).
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(ctxKey{}).(string); ok {
+		return id
+	}
+	if id, ok := ctx.Value(ContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
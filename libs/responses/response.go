@@ -75,17 +75,35 @@ type PaginatedResponse struct {
 	Message    string      `json:"message"`
 	Data       interface{} `json:"data"`
 	Pagination Pagination  `json:"pagination"`
+	RequestID  string      `json:"request_id,omitempty"`
+	Meta       *Meta       `json:"meta,omitempty"`
 	Timestamp  time.Time   `json:"timestamp"`
 }
 
+// Meta represents optional envelope metadata (RESPONSE_META=true)
+type Meta struct {
+	ProcessingTime string           `json:"processing_time"`
+	APIVersion     string           `json:"api_version,omitempty"`
+	RequestID      string           `json:"request_id,omitempty"`
+	Deprecation    *DeprecationMeta `json:"deprecation,omitempty"`
+}
+
+// DeprecationMeta represents a deprecation notice for the API version that served the request
+type DeprecationMeta struct {
+	Deprecated bool       `json:"deprecated"`
+	Sunset     *time.Time `json:"sunset,omitempty"`
+	Message    string     `json:"message"`
+}
+
 // Pagination represents pagination information
 type Pagination struct {
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
-	Total      int64 `json:"total"`
-	TotalPages int   `json:"total_pages"`
-	HasNext    bool  `json:"has_next"`
-	HasPrev    bool  `json:"has_prev"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // MetricsResponse represents API metrics
@@ -0,0 +1,47 @@
+package responses
+
+import (
+	"response-std/app/models/entities"
+	"response-std/app/pkg/auth"
+	"time"
+)
+
+// TokenResponse adalah personal access token tanpa hash-nya
+type TokenResponse struct {
+	ID                uint       `json:"id"`
+	Name              string     `json:"name"`
+	Abilities         []string   `json:"abilities"`
+	LastUsedAt        *time.Time `json:"last_used_at"`
+	LastUsedIP        *string    `json:"last_used_ip"`
+	LastUsedUserAgent *string    `json:"last_used_user_agent"`
+	CreatedIP         *string    `json:"created_ip"`
+	CreatedUserAgent  *string    `json:"created_user_agent"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Current           bool       `json:"current"`
+}
+
+// TokenToResponse mengubah token menjadi respons; currentID adalah token yang dipakai request ini
+func TokenToResponse(t *entities.PersonalAccessTokens, currentID uint) TokenResponse {
+	return TokenResponse{
+		ID:                t.ID,
+		Name:              t.Name,
+		Abilities:         auth.ParseAbilities(t.Abilities),
+		LastUsedAt:        t.LastUsedAt,
+		LastUsedIP:        t.LastUsedIP,
+		LastUsedUserAgent: t.LastUsedUserAgent,
+		CreatedIP:         t.CreatedIP,
+		CreatedUserAgent:  t.CreatedUserAgent,
+		ExpiresAt:         t.ExpiresAt,
+		CreatedAt:         t.CreatedAt,
+		Current:           currentID != 0 && t.ID == currentID,
+	}
+}
+
+func TokensToResponse(tokens []entities.PersonalAccessTokens, currentID uint) []TokenResponse {
+	list := make([]TokenResponse, len(tokens))
+	for i := range tokens {
+		list[i] = TokenToResponse(&tokens[i], currentID)
+	}
+	return list
+}
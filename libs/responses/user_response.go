@@ -2,17 +2,44 @@ package responses
 
 import (
 	"response-std/app/models/entities"
+	"response-std/app/pkg/resource"
 	"time"
 )
 
 type UserResponse struct {
-	ID              uint             `json:"id,omitempty"`
-	Name            string           `json:"name"`
-	Email           string           `json:"email"`
-	EmailVerifiedAt *time.Time       `json:"email_verified_at,omitempty"`
-	CreatedAt       time.Time        `json:"created_at,omitempty"`
-	UpdatedAt       time.Time        `json:"updated_at,omitempty"`
-	Roles           []entities.Roles `json:"roles,omitempty"`
+	ID              uint                 `json:"id,omitempty"`
+	Name            string               `json:"name"`
+	Email           string               `json:"email"`
+	EmailVerifiedAt *time.Time           `json:"email_verified_at,omitempty"`
+	Locale          *string              `json:"locale,omitempty"`
+	CreatedAt       time.Time            `json:"created_at,omitempty"`
+	UpdatedAt       time.Time            `json:"updated_at,omitempty"`
+	Roles           []RoleResponse       `json:"roles,omitempty"`
+	Permissions     []PermissionResponse `json:"permissions,omitempty"`
+}
+
+type RoleResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	GuardName   string               `json:"guard_name"`
+	Permissions []PermissionResponse `json:"permissions,omitempty"`
+}
+
+type PermissionResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	GuardName string `json:"guard_name"`
+}
+
+// UserResource adalah field & include yang boleh diminta untuk resource user
+// (?fields=id,name,email&include=roles,roles.permissions)
+var UserResource = resource.Spec{
+	Fields: []string{"id", "name", "email", "email_verified_at", "locale", "created_at", "updated_at"},
+	Includes: map[string]string{
+		"roles":             "Roles",
+		"roles.permissions": "Roles.Permissions",
+		"permissions":       "Permissions",
+	},
 }
 
 func UserToResponse(u *entities.User) UserResponse {
@@ -21,8 +48,43 @@ func UserToResponse(u *entities.User) UserResponse {
 		Name:            u.Name,
 		Email:           u.Email,
 		EmailVerifiedAt: u.EmailVerifiedAt,
+		Locale:          u.Locale,
 		CreatedAt:       u.CreatedAt,
 		UpdatedAt:       u.UpdatedAt,
-		Roles:           u.Roles,
+		Roles:           RolesToResponse(u.Roles),
+		Permissions:     PermissionsToResponse(u.Permissions),
+	}
+}
+
+func RolesToResponse(roles []entities.Roles) []RoleResponse {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	list := make([]RoleResponse, len(roles))
+	for i, r := range roles {
+		list[i] = RoleResponse{
+			ID:          r.ID,
+			Name:        r.Name,
+			GuardName:   r.GuardName,
+			Permissions: PermissionsToResponse(r.Permissions),
+		}
+	}
+	return list
+}
+
+func PermissionsToResponse(perms []entities.Permission) []PermissionResponse {
+	if len(perms) == 0 {
+		return nil
+	}
+
+	list := make([]PermissionResponse, len(perms))
+	for i, p := range perms {
+		list[i] = PermissionResponse{
+			ID:        p.ID,
+			Name:      p.Name,
+			GuardName: p.GuardName,
+		}
 	}
+	return list
 }
@@ -1,6 +1,13 @@
 package router
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 type RouteSetupFunc func(*gin.Engine)
 
@@ -9,3 +16,111 @@ var RouteRegistry = make(map[string]RouteSetupFunc)
 func Register(version string, setup RouteSetupFunc) {
 	RouteRegistry[version] = setup
 }
+
+// Deprecation menandai satu API version sebagai deprecated, Sunset opsional
+type Deprecation struct {
+	Version string
+	Sunset  *time.Time
+}
+
+var (
+	// "METHOD /full/path" -> version yang mendaftarkan route tersebut
+	routeVersions = make(map[string]string)
+	// prefix statis path route (sebelum :param / *wildcard) -> version, untuk VersionForPath
+	pathPrefixes  = make(map[string]string)
+	deprecations  = make(map[string]Deprecation)
+	registryMutex sync.RWMutex
+)
+
+// Setup menjalankan setup func untuk version dan mencatat route yang didaftarkannya,
+// sehingga VersionOf bisa menjawab version mana yang melayani sebuah request.
+func Setup(r *gin.Engine, version string, setup RouteSetupFunc) {
+	before := make(map[string]bool)
+	for _, route := range r.Routes() {
+		before[routeKey(route.Method, route.Path)] = true
+	}
+
+	setup(r)
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	for _, route := range r.Routes() {
+		key := routeKey(route.Method, route.Path)
+		if !before[key] {
+			routeVersions[key] = version
+			pathPrefixes[staticPrefix(route.Path)] = version
+		}
+	}
+}
+
+// VersionOf mengembalikan version untuk route (pakai c.FullPath()), "" jika tidak dikenal
+func VersionOf(method, fullPath string) string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return routeVersions[routeKey(method, fullPath)]
+}
+
+// VersionForPath menebak version dari path request saja (tanpa method), dengan prefix
+// route terpanjang yang cocok. Dipakai middleware yang berjalan sebelum routing selesai,
+// mis. preflight CORS (OPTIONS) yang tidak punya route sendiri.
+func VersionForPath(path string) string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	var version, longest string
+	for prefix, v := range pathPrefixes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(longest) {
+			longest, version = prefix, v
+		}
+	}
+	return version
+}
+
+// Deprecate menandai version sebagai deprecated
+func Deprecate(d Deprecation) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	deprecations[d.Version] = d
+}
+
+// DeprecationOf mengembalikan info deprecation untuk version
+func DeprecationOf(version string) (Deprecation, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	d, ok := deprecations[version]
+	return d, ok
+}
+
+// LoadDeprecations membaca DEPRECATED_API_VERSIONS, format "version" atau "version:YYYY-MM-DD" (sunset)
+// contoh: DEPRECATED_API_VERSIONS=v1:2026-12-31,web
+func LoadDeprecations(entries []string) error {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		version, sunset, hasSunset := strings.Cut(entry, ":")
+		d := Deprecation{Version: strings.TrimSpace(version)}
+		if hasSunset {
+			t, err := time.Parse("2006-01-02", strings.TrimSpace(sunset))
+			if err != nil {
+				return fmt.Errorf("invalid sunset date for %s: %w", d.Version, err)
+			}
+			d.Sunset = &t
+		}
+		Deprecate(d)
+	}
+	return nil
+}
+
+func staticPrefix(path string) string {
+	if i := strings.IndexAny(path, ":*"); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
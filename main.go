@@ -1,6 +1,12 @@
 package main
 
 import (
+	"response-std/app/http/middleware"
+	"response-std/app/pkg/auth"
+	"response-std/app/pkg/i18n"
+	"response-std/app/pkg/idempotency"
+	"response-std/app/pkg/lockout"
+	"response-std/app/pkg/ratelimit"
 	"response-std/config"
 	"response-std/libs/external/services"
 	"response-std/libs/external/services/hooks"
@@ -20,13 +26,50 @@ func main() {
 
 	var log = services.AppLogger
 
-	r := gin.Default()
+	// Load katalog bahasa tambahan dari LANG_DIR (katalog bawaan sudah ter-embed)
+	if err := i18n.Load(config.ENV.LangDir); err != nil {
+		log.Warn("Failed to load language catalogs", map[string]interface{}{
+			"lang_dir": config.ENV.LangDir,
+			"error":    err.Error(),
+		})
+	}
+
+	// gin.New tanpa Logger/Recovery bawaan gin: access log & recovery memakai middleware repo (log terstruktur)
+	r := gin.New()
+	// Tanpa ini gin mempercayai X-Forwarded-For dari siapa saja, sehingga rate limit & lockout per IP bisa di-bypass
+	if err := r.SetTrustedProxies(config.ENV.TrustedProxies); err != nil {
+		panic("Invalid TRUSTED_PROXIES: " + err.Error())
+	}
+	// Request ID, meta, access log, recovery, kompresi, timeout & body limit dipasang sebelum route version manapun agar berlaku global
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.ResponseMetaMiddleware())
+	r.Use(middleware.LoggingMiddleware(log))
+	r.Use(middleware.ErrorHandlingMiddleware(log))
+	r.Use(middleware.CompressionMiddleware())
+	r.Use(middleware.TimeoutMiddleware())
+	r.Use(middleware.BodyLimitMiddleware())
+
+	if err := ratelimit.Init(config.ENV); err != nil {
+		panic("Invalid rate limit configuration: " + err.Error())
+	}
+
+	lockout.Init(config.ENV)
+	idempotency.Init(config.ENV, config.DB)
+
+	if err := auth.InitJWT(config.ENV); err != nil {
+		panic("Invalid JWT configuration: " + err.Error())
+	}
+
+	if err := router.LoadDeprecations(config.ENV.DeprecatedAPIVersions); err != nil {
+		panic("Invalid DEPRECATED_API_VERSIONS: " + err.Error())
+	}
+
 	for _, version := range config.ENV.API_VERSION {
 		setup, ok := router.RouteRegistry[version]
 		if !ok {
 			panic("Unsupported API version: " + version)
 		}
-		setup(r)
+		router.Setup(r, version, setup)
 	}
 
 	// Initialize Gin router
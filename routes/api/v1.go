@@ -4,6 +4,8 @@ import (
 	"response-std/app/http/controllers"
 	"response-std/app/http/middleware"
 	"response-std/app/pkg/permissions"
+	"response-std/app/pkg/ratelimit"
+	"response-std/app/pkg/response"
 	"response-std/config"
 	"response-std/libs/external/handlers"
 	"response-std/libs/external/services"
@@ -16,6 +18,7 @@ import (
 func SetupRoutesv1(r *gin.Engine) {
 	// Initialize controllers
 	authController := controllers.NewAuthController()
+	tokenController := controllers.NewTokenController(config.DB)
 
 	// Initialize services
 	logger := services.NewLogger(config.ENV.LogLevel, config.ENV.Environment)
@@ -23,10 +26,10 @@ func SetupRoutesv1(r *gin.Engine) {
 	apiHandler := handlers.NewAPIHandler(apiClient, logger)
 
 	// Global middlewares
-	r.Use(gin.Recovery())
+	// Access log & recovery sudah dipasang global di main.go
 	r.Use(middleware.CORSMiddleware())
-	r.Use(middleware.LoggingMiddleware(logger))
-	r.Use(middleware.ErrorHandlingMiddleware(logger))
+	r.Use(middleware.SecurityHeadersMiddleware())
+	r.Use(middleware.ErrorResponseMiddleware())
 	r.Use(middleware.RateLimitMiddleware())
 
 	// register upload routes
@@ -44,6 +47,7 @@ func SetupRoutesv1(r *gin.Engine) {
 
 	// API routes
 	api := r.Group("/api/v1")
+	api.Use(middleware.ErrorFormatMiddleware(config.ENV.ErrorFormatFor("v1")))
 	{
 		api.GET("/hello", func(c *gin.Context) {
 			c.JSON(200, gin.H{"message": "Hello from V1"})
@@ -54,25 +58,42 @@ func SetupRoutesv1(r *gin.Engine) {
 		// Authentication routes (public)
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authController.Login(config.DB))
-			auth.POST("/register", authController.Register(config.DB, permissions.NewSpatie(config.DB)))
+			auth.POST("/login", middleware.RateLimit(ratelimit.PolicyLogin), authController.Login(config.DB))
+			auth.POST("/register", middleware.Idempotency(), authController.Register(config.DB, permissions.NewSpatie(config.DB)))
+			// Refresh memakai refresh token di body, bukan Bearer access token (yang mungkin sudah kadaluarsa)
+			auth.POST("/refresh", authController.RefreshToken(config.DB))
 		}
 
 		// Protected routes (require authentication)
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(config.DB))
+		protected.Use(middleware.RateLimit(ratelimit.PolicyUser))
 		{
 			// Auth endpoints
 			protected.POST("/auth/logout", authController.Logout(config.DB))
-			protected.POST("/auth/refresh", authController.RefreshToken(config.DB))
 			protected.GET("/auth/me", func(c *gin.Context) {
 				authController.Me(c, permissions.NewSpatie(config.DB))
 			})
 
+			// Personal access token milik sendiri
+			protected.GET("/auth/tokens", middleware.TokenCan("tokens:read"), response.Handle(tokenController.ListTokens))
+			protected.POST("/auth/tokens", middleware.TokenCan("tokens:write"), response.Handle(tokenController.CreateToken))
+			protected.DELETE("/auth/tokens/others", middleware.TokenCan("tokens:write"), response.Handle(tokenController.RevokeOtherTokens))
+			protected.DELETE("/auth/tokens/:token_id", middleware.TokenCan("tokens:write"), response.Handle(tokenController.RevokeToken))
+
 			// Admin routes (require admin role)
 			admin := protected.Group("/admin")
 			admin.Use(middleware.RoleMiddleware("admin"))
 			{
+				// Buka lockout login (username dan/atau IP)
+				admin.POST("/auth/unlock", authController.UnlockLogin())
+
+				// Kelola personal access token milik user lain (ability token admin tetap berlaku)
+				admin.GET("/users/:id/tokens", middleware.TokenCan("tokens:read"), response.Handle(tokenController.ListUserTokens))
+				admin.POST("/users/:id/tokens", middleware.TokenCan("tokens:write"), response.Handle(tokenController.CreateUserToken))
+				admin.DELETE("/users/:id/tokens", middleware.TokenCan("tokens:write"), response.Handle(tokenController.RevokeUserTokens))
+				admin.DELETE("/users/:id/tokens/:token_id", middleware.TokenCan("tokens:write"), response.Handle(tokenController.RevokeUserToken))
+
 				// Example admin routes
 				// admin.GET("/dashboard", adminController.Dashboard)
 				// admin.GET("/users", adminController.GetAllUsers)
@@ -4,6 +4,8 @@ import (
 	"response-std/app/http/controllers"
 	"response-std/app/http/middleware"
 	"response-std/app/pkg/permissions"
+	"response-std/app/pkg/ratelimit"
+	"response-std/app/pkg/response"
 	"response-std/config"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +17,9 @@ func SetupWebRoutes(r *gin.Engine) {
 
 	// Semua routing v2
 	api := r.Group("/api/web")
+	api.Use(middleware.SecurityHeadersMiddleware())
+	api.Use(middleware.ErrorFormatMiddleware(config.ENV.ErrorFormatFor("web")))
+	api.Use(middleware.ErrorResponseMiddleware())
 	api.GET("/hello", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Hello from web API!"})
 	})
@@ -28,12 +33,14 @@ func SetupWebRoutes(r *gin.Engine) {
 	// Protected routes
 	protected := api.Group("/")
 	protected.Use(middleware.AuthMiddleware(config.DB))
+	protected.Use(middleware.RateLimit(ratelimit.PolicyUser))
 	user := protected.Group("/users")
 	{
-		user.GET("/", userController.ListUser)
-		user.GET("/:id", userController.GetUserByID)
-		user.POST("/", userController.CreateUser)
-		user.PUT("/:id/update", userController.UpdateUser)
-		user.DELETE("/:id/delete", userController.DeleteUser)
+		// Token ability: "users:read" / "users:write" / "users:delete" (atau "users:*", "*")
+		user.GET("/", middleware.TokenCan("users:read"), response.Handle(userController.ListUser))
+		user.GET("/:id", middleware.TokenCan("users:read"), response.Handle(userController.GetUserByID))
+		user.POST("/", middleware.TokenCan("users:write"), middleware.Idempotency(), response.Handle(userController.CreateUser))
+		user.PUT("/:id/update", middleware.TokenCan("users:write"), response.Handle(userController.UpdateUser))
+		user.DELETE("/:id/delete", middleware.TokenCan("users:delete"), response.Handle(userController.DeleteUser))
 	}
 }